@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/macrat/tako-lang/tako"
+)
+
+func checkSource(filename string) (int, error) {
+	file := os.Stdin
+	if filename != "" {
+		var err error
+		file, err = os.Open(filename)
+		if err != nil {
+			return 0, err
+		}
+		defer file.Close()
+	}
+
+	errs, expr := tako.Parse(file, file.Name())
+	if len(errs) > 0 {
+		for _, e := range errs {
+			fmt.Fprintln(os.Stderr, e.Error())
+		}
+		return len(errs), nil
+	}
+
+	problems := tako.Analyze(expr).Check()
+	for _, p := range problems {
+		fmt.Fprintln(os.Stderr, p.Error())
+	}
+
+	return len(problems), nil
+}
+
+func checkSources(files []string) {
+	if len(files) == 0 {
+		files = []string{""}
+	}
+
+	failed := false
+	for _, f := range files {
+		n, err := checkSource(f)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+		}
+		if n > 0 || err != nil {
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		diff   string
+	}{
+		{
+			"same",
+			"a\nb\n",
+			"a\nb\n",
+			"",
+		},
+		{
+			"change in middle",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			"1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			"--- x.orig\n+++ x\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			"separate hunks",
+			"a\n1\n2\n3\n4\n5\n6\n7\nb\n",
+			"A\n1\n2\n3\n4\n5\n6\n7\nB\n",
+			"--- x.orig\n+++ x\n@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n 3\n@@ -6,4 +6,4 @@\n 5\n 6\n 7\n-b\n+B\n",
+		},
+		{
+			"insert into empty",
+			"",
+			"a\n",
+			"--- x.orig\n+++ x\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			"missing newline",
+			"a",
+			"a\n",
+			"--- x.orig\n+++ x\n@@ -1 +1 @@\n-a\n\\ No newline at end of file\n+a\n",
+		},
+	}
+
+	for _, tt := range tests {
+		if diff := unifiedDiff("x.orig", "x", tt.before, tt.after); diff != tt.diff {
+			t.Errorf("%s: unexpected diff:\n%s", tt.name, diff)
+		}
+	}
+}
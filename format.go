@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/macrat/tako-lang/tako"
+)
+
+func formatSource(src []byte, filename string) (string, error) {
+	errs, expr, comments := tako.ParseWithComments(bytes.NewReader(src), filename)
+	if len(errs) > 0 {
+		return "", errs[0]
+	}
+
+	return tako.Format(expr, comments, string(src)), nil
+}
+
+func formatFile(filename string) error {
+	var src []byte
+	var err error
+	if filename == "" {
+		src, err = ioutil.ReadAll(os.Stdin)
+		filename = "<stdin>"
+	} else {
+		src, err = ioutil.ReadFile(filename)
+	}
+	if err != nil {
+		return err
+	}
+
+	formatted, err := formatSource(src, filename)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case *fmtDiff:
+		if formatted == string(src) {
+			return nil
+		}
+		fmt.Print(unifiedDiff(filename+".orig", filename, string(src), formatted))
+
+	case *fmtWrite:
+		if filename == "<stdin>" {
+			return fmt.Errorf("can not use -w with standard input")
+		}
+		if formatted == string(src) {
+			return nil
+		}
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filename, []byte(formatted), info.Mode())
+
+	default:
+		fmt.Print(formatted)
+	}
+
+	return nil
+}
+
+func formatFiles(files []string) {
+	if len(files) == 0 {
+		files = []string{""}
+	}
+
+	failed := false
+	for _, f := range files {
+		if err := formatFile(f); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,355 @@
+package lsp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/macrat/tako-lang/tako"
+)
+
+func parse(uri, text string) (errs []tako.SyntaxError, expr tako.Expression, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to parse: %v", r)
+		}
+	}()
+
+	errs, expr = tako.Parse(strings.NewReader(text), uri)
+	return
+}
+
+func analyze(expr tako.Expression, check bool) (analysis *tako.Analysis, warnings []tako.LocatedError, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			analysis, warnings, err = nil, nil, fmt.Errorf("failed to analyze: %v", r)
+		}
+	}()
+
+	analysis = tako.Analyze(expr)
+	if check {
+		warnings = analysis.Check()
+	}
+	return
+}
+
+func (s *Server) update(uri, text string) {
+	doc, ok := s.documents[uri]
+	if !ok {
+		doc = &document{}
+		s.documents[uri] = doc
+	}
+	doc.text = text
+
+	diagnostics := []Diagnostic{}
+
+	errs, expr, err := parse(uri, text)
+	if err != nil {
+		diagnostics = append(diagnostics, Diagnostic{
+			Severity: severityError,
+			Source:   "tako",
+			Message:  err.Error(),
+		})
+	}
+	for _, e := range errs {
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    doc.toRange(e.Position(), e.Literal()),
+			Severity: severityError,
+			Source:   "tako",
+			Message:  e.Message(),
+		})
+	}
+	if expr != nil {
+		analysis, warnings, err := analyze(expr, len(errs) == 0)
+		if err != nil {
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: severityError,
+				Source:   "tako",
+				Message:  err.Error(),
+			})
+		} else {
+			doc.analysis = analysis
+		}
+
+		for _, e := range warnings {
+			diagnostics = append(diagnostics, Diagnostic{
+				Range:    doc.errorRange(e),
+				Severity: severityWarning,
+				Source:   "tako",
+				Message:  e.Message(),
+			})
+		}
+	}
+
+	s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: diagnostics,
+	})
+}
+
+func (doc *document) line(n int) []rune {
+	lines := strings.Split(doc.text, "\n")
+	if n < 0 || n >= len(lines) {
+		return nil
+	}
+	return []rune(lines[n])
+}
+
+func utf16Length(rs []rune) int {
+	n := 0
+	for _, r := range rs {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+func (doc *document) toPosition(pos tako.Position) Position {
+	line := doc.line(pos.Line)
+
+	column := pos.Column
+	if column > len(line) {
+		column = len(line)
+	}
+
+	return Position{
+		Line:      pos.Line,
+		Character: utf16Length(line[:column]) + pos.Column - column,
+	}
+}
+
+func (doc *document) fromPosition(pos Position) tako.Position {
+	line := doc.line(pos.Line)
+
+	column := 0
+	for units := 0; column < len(line) && units < pos.Character; column++ {
+		units += utf16Length(line[column : column+1])
+	}
+
+	var result tako.Position
+	result.Line = pos.Line
+	result.Column = column
+	return result
+}
+
+func (doc *document) toRange(pos tako.Position, literal string) Range {
+	end := pos
+	for _, c := range literal {
+		if c == '\n' {
+			end.Line++
+			end.Column = 0
+		} else {
+			end.Column++
+		}
+	}
+
+	return Range{
+		Start: doc.toPosition(pos),
+		End:   doc.toPosition(end),
+	}
+}
+
+func (doc *document) identifierRange(ident tako.Identifier) Range {
+	return doc.toRange(ident.Pos, ident.Key)
+}
+
+func (doc *document) errorRange(err tako.LocatedError) Range {
+	switch e := err.(type) {
+	case tako.NotDefinedError:
+		return doc.identifierRange(tako.Identifier(e))
+	case tako.AlreadyDefinedError:
+		return doc.identifierRange(tako.Identifier(e))
+	}
+
+	line := doc.line(err.Position().Line)
+	if c := err.Position().Column; c < len(line) {
+		return doc.toRange(err.Position(), string(line[c]))
+	}
+	return doc.toRange(err.Position(), " ")
+}
+
+func (doc *document) contains(ident tako.Identifier) bool {
+	line := doc.line(ident.Pos.Line)
+	if ident.Pos.Column > len(line) {
+		return false
+	}
+	return strings.HasPrefix(string(line[ident.Pos.Column:]), ident.Key)
+}
+
+func isWordChar(r rune) bool {
+	return r == '_' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9'
+}
+
+func describe(name string, value tako.Expression) string {
+	if value == nil {
+		return fmt.Sprintf("(argument) %s", name)
+	}
+	if _, ok := value.(tako.Function); ok {
+		return tako.Signature(name, value)
+	}
+	return fmt.Sprintf("(variable) %s", name)
+}
+
+func (s *Server) reference(doc *document, pos tako.Position) (tako.Reference, bool) {
+	if doc.analysis == nil {
+		return tako.Reference{}, false
+	}
+
+	ref, ok := doc.analysis.ReferenceAt(pos)
+	if !ok || !doc.contains(ref.Name) || ref.Definition != nil && !doc.contains(ref.Definition.Name) {
+		return tako.Reference{}, false
+	}
+	return ref, true
+}
+
+func (s *Server) hover(uri string, doc *document, pos tako.Position) interface{} {
+	ref, ok := s.reference(doc, pos)
+	if !ok {
+		return nil
+	}
+
+	var text, docs string
+	if ref.Definition != nil {
+		text = describe(ref.Name.Key, ref.Definition.Value)
+		if fd, ok := ref.Definition.Value.(tako.FunctionDefine); ok && fd.Doc != "" {
+			docs = "\n\n" + fd.Doc
+		}
+	} else if v, ok := tako.GetBuiltin(ref.Name.Key); ok {
+		text = "(builtin) " + describe(ref.Name.Key, v)
+	} else {
+		text = fmt.Sprintf("%s is not defined", ref.Name.Key)
+	}
+
+	return Hover{
+		Contents: MarkupContent{
+			Kind:  "markdown",
+			Value: "```tako\n" + text + "\n```" + docs,
+		},
+		Range: doc.identifierRange(ref.Name),
+	}
+}
+
+func (s *Server) definition(uri string, doc *document, pos tako.Position) interface{} {
+	ref, ok := s.reference(doc, pos)
+	if !ok || ref.Definition == nil {
+		return nil
+	}
+
+	return Location{
+		URI:   uri,
+		Range: doc.identifierRange(ref.Definition.Name),
+	}
+}
+
+func (s *Server) completion(uri string, doc *document, pos tako.Position) interface{} {
+	line := doc.line(pos.Line)
+	if pos.Column < len(line) {
+		line = line[:pos.Column]
+	}
+
+	start := len(line)
+	for start > 0 && isWordChar(line[start-1]) {
+		start--
+	}
+	prefix := string(line[start:])
+
+	items := []CompletionItem{}
+
+	if start > 0 && line[start-1] == '.' {
+		for _, name := range tako.MethodNames() {
+			if strings.HasPrefix(name, prefix) {
+				v, _ := tako.GetBuiltin(name)
+				items = append(items, CompletionItem{
+					Label:  name,
+					Kind:   completionKindMethod,
+					Detail: tako.Signature(name, v),
+				})
+			}
+		}
+		return items
+	}
+
+	seen := make(map[string]bool)
+
+	if doc.analysis != nil {
+		scope := doc.analysis.ScopeAt(pos)
+
+		for _, name := range scope.Names() {
+			if strings.HasPrefix(name, prefix) {
+				seen[name] = true
+
+				d := scope.Lookup(name)
+				kind := completionKindVariable
+				if _, ok := d.Value.(tako.Function); ok {
+					kind = completionKindFunction
+				}
+				items = append(items, CompletionItem{
+					Label:  name,
+					Kind:   kind,
+					Detail: describe(name, d.Value),
+				})
+			}
+		}
+	}
+
+	for _, name := range tako.BuiltinNames() {
+		if !seen[name] && isWordChar(rune(name[0])) && strings.HasPrefix(name, prefix) {
+			v, _ := tako.GetBuiltin(name)
+			kind := completionKindVariable
+			if _, ok := v.(tako.Function); ok {
+				kind = completionKindFunction
+			}
+			items = append(items, CompletionItem{
+				Label:  name,
+				Kind:   kind,
+				Detail: describe(name, v),
+			})
+		}
+	}
+
+	return items
+}
+
+func (s *Server) documentSymbol(uri string) interface{} {
+	doc, ok := s.documents[uri]
+	if !ok || doc.analysis == nil {
+		return nil
+	}
+
+	return doc.symbols(doc.analysis.Root)
+}
+
+func (doc *document) symbols(scope *tako.Scope) []DocumentSymbol {
+	result := []DocumentSymbol{}
+
+	for _, d := range scope.Definitions {
+		if d.Value == nil {
+			continue
+		}
+
+		sym := DocumentSymbol{
+			Name:           d.Name.Key,
+			Kind:           symbolKindVariable,
+			Range:          doc.identifierRange(d.Name),
+			SelectionRange: doc.identifierRange(d.Name),
+		}
+
+		if _, ok := d.Value.(tako.Function); ok {
+			sym.Kind = symbolKindFunction
+			sym.Detail = tako.Signature(d.Name.Key, d.Value)
+		}
+
+		if d.Body != nil {
+			sym.Children = doc.symbols(d.Body)
+			if d.Body.End.Line > d.Name.Pos.Line {
+				sym.Range.End = doc.toPosition(d.Body.End)
+			}
+		}
+
+		result = append(result, sym)
+	}
+
+	return result
+}
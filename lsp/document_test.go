@@ -0,0 +1,41 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/macrat/tako-lang/tako"
+)
+
+func TestUTF16Position(t *testing.T) {
+	doc := &document{text: "a := \"\U0001F419é\"; b := 1\n\U0001F419"}
+
+	tests := []struct {
+		line, column int
+		character    int
+	}{
+		{0, 0, 0},
+		{0, 6, 6},
+		{0, 7, 8},
+		{0, 8, 9},
+		{0, 11, 12},
+		{1, 1, 2},
+		{1, 5, 6},
+	}
+
+	for _, tt := range tests {
+		var pos tako.Position
+		pos.Line = tt.line
+		pos.Column = tt.column
+
+		if got := doc.toPosition(pos); got.Line != tt.line || got.Character != tt.character {
+			t.Errorf("toPosition(%d:%d): expected character %d but got %d", tt.line, tt.column, tt.character, got.Character)
+		}
+
+		if tt.column > len(doc.line(tt.line)) {
+			continue
+		}
+		if got := doc.fromPosition(Position{Line: tt.line, Character: tt.character}); got.Line != tt.line || got.Column != tt.column {
+			t.Errorf("fromPosition(%d:%d): expected column %d but got %d", tt.line, tt.character, tt.column, got.Column)
+		}
+	}
+}
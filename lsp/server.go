@@ -0,0 +1,228 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/macrat/tako-lang/tako"
+)
+
+type document struct {
+	text     string
+	errors   []tako.SyntaxError
+	analysis *tako.Analysis
+}
+
+type Server struct {
+	reader *bufio.Reader
+	writer io.Writer
+	lock   sync.Mutex
+
+	documents map[string]*document
+}
+
+func NewServer(reader io.Reader, writer io.Writer) *Server {
+	return &Server{
+		reader:    bufio.NewReader(reader),
+		writer:    writer,
+		documents: make(map[string]*document),
+	}
+}
+
+func (s *Server) Serve() error {
+	for {
+		req, err := s.read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if req.Method == "exit" {
+			return nil
+		}
+
+		result, rerr := s.safeHandle(req)
+		if req.ID == nil {
+			continue
+		}
+
+		var resp interface{} = response{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Result:  result,
+		}
+		if rerr != nil {
+			resp = errorResponse{
+				JSONRPC: "2.0",
+				ID:      req.ID,
+				Error:   rerr,
+			}
+		}
+		if err := s.write(resp); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) read() (request, error) {
+	var req request
+
+	header, err := textproto.NewReader(s.reader).ReadMIMEHeader()
+	if err != nil {
+		return req, err
+	}
+
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return req, fmt.Errorf("invalid Content-Length: %s", header.Get("Content-Length"))
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.reader, body); err != nil {
+		return req, err
+	}
+
+	return req, json.Unmarshal(body, &req)
+}
+
+func (s *Server) write(message interface{}) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, err = fmt.Fprintf(s.writer, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	return s.write(notification{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	})
+}
+
+func (s *Server) safeHandle(req request) (result interface{}, rerr *responseError) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, rerr = nil, &responseError{
+				Code:    codeInternalError,
+				Message: fmt.Sprintf("internal error: %v", r),
+			}
+		}
+	}()
+
+	return s.handle(req)
+}
+
+func (s *Server) handle(req request) (interface{}, *responseError) {
+	switch req.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":       1,
+				"hoverProvider":          true,
+				"definitionProvider":     true,
+				"documentSymbolProvider": true,
+				"completionProvider": map[string]interface{}{
+					"triggerCharacters": []string{"."},
+				},
+			},
+			"serverInfo": map[string]string{
+				"name": "tako-lang",
+			},
+		}, nil
+
+	case "shutdown":
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		s.update(params.TextDocument.URI, params.TextDocument.Text)
+		return nil, nil
+
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		if len(params.ContentChanges) > 0 {
+			s.update(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+		}
+		return nil, nil
+
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		delete(s.documents, params.TextDocument.URI)
+		s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+		return nil, nil
+
+	case "textDocument/hover":
+		return s.withPosition(req, s.hover)
+
+	case "textDocument/definition":
+		return s.withPosition(req, s.definition)
+
+	case "textDocument/completion":
+		return s.withPosition(req, s.completion)
+
+	case "textDocument/documentSymbol":
+		var params struct {
+			TextDocument TextDocumentIdentifier `json:"textDocument"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		return s.documentSymbol(params.TextDocument.URI), nil
+	}
+
+	if req.ID == nil || strings.HasPrefix(req.Method, "$/") || req.Method == "initialized" {
+		return nil, nil
+	}
+
+	return nil, &responseError{
+		Code:    codeMethodNotFound,
+		Message: fmt.Sprintf("method not found: %s", req.Method),
+	}
+}
+
+func (s *Server) withPosition(req request, f func(string, *document, tako.Position) interface{}) (interface{}, *responseError) {
+	var params TextDocumentPositionParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return nil, invalidParams(err)
+	}
+
+	doc, ok := s.documents[params.TextDocument.URI]
+	if !ok {
+		return nil, nil
+	}
+
+	return f(params.TextDocument.URI, doc, doc.fromPosition(params.Position)), nil
+}
+
+func invalidParams(err error) *responseError {
+	return &responseError{
+		Code:    codeInvalidParams,
+		Message: err.Error(),
+	}
+}
@@ -0,0 +1,226 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"testing"
+)
+
+type message map[string]interface{}
+
+func session(t *testing.T, requests ...message) []message {
+	t.Helper()
+
+	var input bytes.Buffer
+	for _, r := range requests {
+		r["jsonrpc"] = "2.0"
+		body, err := json.Marshal(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&input, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+
+	var output bytes.Buffer
+	if err := NewServer(&input, &output).Serve(); err != nil {
+		t.Fatal(err)
+	}
+
+	var result []message
+	reader := bufio.NewReader(&output)
+	for {
+		header, err := textproto.NewReader(reader).ReadMIMEHeader()
+		if err == io.EOF {
+			return result
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		length, err := strconv.Atoi(header.Get("Content-Length"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body := make([]byte, length)
+		if _, err := io.ReadFull(reader, body); err != nil {
+			t.Fatal(err)
+		}
+
+		var m message
+		if err := json.Unmarshal(body, &m); err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, m)
+	}
+}
+
+func open(text string) message {
+	return message{
+		"method": "textDocument/didOpen",
+		"params": message{
+			"textDocument": message{"uri": "file:///test.tako", "text": text},
+		},
+	}
+}
+
+func at(id int, method string, line, character int) message {
+	return message{
+		"id":     id,
+		"method": method,
+		"params": message{
+			"textDocument": message{"uri": "file:///test.tako"},
+			"position":     message{"line": line, "character": character},
+		},
+	}
+}
+
+func responseOf(t *testing.T, messages []message, id int) message {
+	t.Helper()
+
+	for _, m := range messages {
+		if i, ok := m["id"].(float64); ok && int(i) == id {
+			return m
+		}
+	}
+	t.Fatalf("no response for request %d: %v", id, messages)
+	return nil
+}
+
+func diagnosticsOf(t *testing.T, messages []message) []interface{} {
+	t.Helper()
+
+	for _, m := range messages {
+		if m["method"] == "textDocument/publishDiagnostics" {
+			return m["params"].(map[string]interface{})["diagnostics"].([]interface{})
+		}
+	}
+	t.Fatalf("no diagnostics published: %v", messages)
+	return nil
+}
+
+func rangeOf(v interface{}) string {
+	r := v.(map[string]interface{})
+	start := r["start"].(map[string]interface{})
+	end := r["end"].(map[string]interface{})
+	return fmt.Sprintf("%v:%v-%v:%v", start["line"], start["character"], end["line"], end["character"])
+}
+
+func TestInitialize(t *testing.T) {
+	resp := responseOf(t, session(t, message{"id": 1, "method": "initialize", "params": message{}}), 1)
+
+	if _, ok := resp["error"]; ok {
+		t.Fatalf("unexpected error: %v", resp)
+	}
+
+	capabilities := resp["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	for _, name := range []string{"hoverProvider", "definitionProvider", "documentSymbolProvider"} {
+		if capabilities[name] != true {
+			t.Errorf("%s is not enabled: %v", name, capabilities)
+		}
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	resp := responseOf(t, session(t, message{"id": 1, "method": "unknown/method"}), 1)
+
+	if _, ok := resp["result"]; ok {
+		t.Errorf("error response must not have result: %v", resp)
+	}
+	if e, ok := resp["error"].(map[string]interface{}); !ok || e["code"] != float64(codeMethodNotFound) {
+		t.Errorf("unexpected error: %v", resp)
+	}
+
+	resp = responseOf(t, session(t, message{"id": 2, "method": "shutdown"}), 2)
+	if result, ok := resp["result"]; !ok || result != nil {
+		t.Errorf("successful response must have null result: %v", resp)
+	}
+	if _, ok := resp["error"]; ok {
+		t.Errorf("successful response must not have error: %v", resp)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		text     string
+		severity float64
+		rng      string
+	}{
+		{"x := (1 +\n", severityError, "0:9-1:0"},
+		{"x := 1\ny := z + x", severityWarning, "1:5-1:6"},
+		{"s := \"\U0001F419\"; y := z", severityWarning, "0:16-0:17"},
+	}
+
+	for _, tt := range tests {
+		diagnostics := diagnosticsOf(t, session(t, open(tt.text)))
+		if len(diagnostics) != 1 {
+			t.Errorf("%q: expected 1 diagnostic but got %v", tt.text, diagnostics)
+			continue
+		}
+
+		d := diagnostics[0].(map[string]interface{})
+		if d["severity"] != tt.severity || rangeOf(d["range"]) != tt.rng {
+			t.Errorf("%q: expected severity %v at %s but got %v", tt.text, tt.severity, tt.rng, d)
+		}
+	}
+
+	if diagnostics := diagnosticsOf(t, session(t, open("x := 1\nx + 1"))); len(diagnostics) != 0 {
+		t.Errorf("expected no diagnostics but got %v", diagnostics)
+	}
+}
+
+func TestHover(t *testing.T) {
+	text := "double := (x){ x * 2 }\ndouble(1)\nprintln(1)"
+	messages := session(t, open(text), at(1, "textDocument/hover", 1, 2), at(2, "textDocument/hover", 2, 3), at(3, "textDocument/hover", 0, 15))
+
+	tests := []struct {
+		id       int
+		contents string
+		rng      string
+	}{
+		{1, "```tako\ndouble(x)\n```", "1:0-1:6"},
+		{2, "```tako\n(builtin) println(args...)\n```", "2:0-2:7"},
+		{3, "```tako\n(argument) x\n```", "0:15-0:16"},
+	}
+
+	for _, tt := range tests {
+		result, ok := responseOf(t, messages, tt.id)["result"].(map[string]interface{})
+		if !ok {
+			t.Errorf("%d: no hover", tt.id)
+			continue
+		}
+
+		contents := result["contents"].(map[string]interface{})["value"]
+		if contents != tt.contents || rangeOf(result["range"]) != tt.rng {
+			t.Errorf("%d: expected %q at %s but got %q at %s", tt.id, tt.contents, tt.rng, contents, rangeOf(result["range"]))
+		}
+	}
+}
+
+func TestDefinition(t *testing.T) {
+	text := "s := \"\U0001F419\"; double := (x){ x * 2 }\ndouble(s)"
+	messages := session(t, open(text), at(1, "textDocument/definition", 1, 1), at(2, "textDocument/definition", 1, 8))
+
+	tests := []struct {
+		id  int
+		rng string
+	}{
+		{1, "0:11-0:17"},
+		{2, "0:0-0:1"},
+	}
+
+	for _, tt := range tests {
+		result, ok := responseOf(t, messages, tt.id)["result"].(map[string]interface{})
+		if !ok {
+			t.Errorf("%d: no definition", tt.id)
+			continue
+		}
+		if r := rangeOf(result["range"]); r != tt.rng {
+			t.Errorf("%d: expected %s but got %s", tt.id, tt.rng, r)
+		}
+	}
+}
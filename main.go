@@ -5,24 +5,58 @@ import (
 	"os"
 
 	"github.com/alecthomas/kingpin"
+
+	"github.com/macrat/tako-lang/lsp"
+	"github.com/macrat/tako-lang/tako"
 )
 
 var (
-	source = kingpin.Arg("source", "source file.").ExistingFile()
-	debug  = kingpin.Flag("debug", "show debug messages.").Bool()
+	debug = kingpin.Flag("debug", "show debug messages.").Bool()
+	useVM = kingpin.Flag("vm", "run on the bytecode virtual machine.").Bool()
+	paths = kingpin.Flag("path", "directory to search for imported files.").Short('I').Strings()
+
+	runCommand = kingpin.Command("run", "run source file, or start REPL if no file given.").Default()
+	source     = runCommand.Arg("source", "source file.").ExistingFile()
+
+	lspCommand = kingpin.Command("lsp", "start language server on stdio.")
+
+	fmtCommand = kingpin.Command("fmt", "format source files.")
+	fmtWrite   = fmtCommand.Flag("write", "write result to source file instead of stdout.").Short('w').Bool()
+	fmtDiff    = fmtCommand.Flag("diff", "display diffs instead of rewriting files.").Short('d').Bool()
+	fmtFiles   = fmtCommand.Arg("files", "source files.").ExistingFiles()
+
+	checkCommand = kingpin.Command("check", "find undefined names and argument mismatches without running.")
+	checkFiles   = checkCommand.Arg("files", "source files.").ExistingFiles()
 )
 
-func Parse(file *os.File) Expression {
-	l := NewLexer(file)
-	l.Filename = file.Name()
+func main() {
+	switch kingpin.Parse() {
+	case runCommand.FullCommand():
+		run()
 
-	yyParse(l)
+	case lspCommand.FullCommand():
+		if err := lsp.NewServer(os.Stdin, os.Stdout).Serve(); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
 
-	return l.result
+	case fmtCommand.FullCommand():
+		formatFiles(*fmtFiles)
+
+	case checkCommand.FullCommand():
+		checkSources(*checkFiles)
+	}
 }
 
-func main() {
-	kingpin.Parse()
+func run() {
+	in := tako.NewInterpreter()
+	in.UseVM = *useVM
+	in.SearchPath = *paths
+
+	if *source == "" && isTerminal(os.Stdin) {
+		repl(in)
+		return
+	}
 
 	file := os.Stdin
 	if *source != "" {
@@ -34,14 +68,31 @@ func main() {
 		}
 	}
 
-	expr := Parse(file)
+	errs, expr := tako.Parse(file, file.Name())
+	if len(errs) > 0 {
+		for _, e := range errs {
+			fmt.Fprintln(os.Stderr, e.Error())
+		}
+		os.Exit(1)
+	}
 
 	if *debug {
 		fmt.Println(expr)
 	}
 
-	ctx := NewContext()
-	if _, err := ctx.ComputeRecursive(expr); err != nil {
+	if _, err := in.Run(expr); err != nil {
+		printError(err)
+	}
+}
+
+func printError(err error) {
+	if te, ok := err.(tako.TracedError); ok {
+		fmt.Fprintln(os.Stderr, te.Traceback())
+	} else {
 		fmt.Fprintln(os.Stderr, err.Error())
 	}
+
+	if ie, ok := err.(tako.InternalError); ok && *debug {
+		os.Stderr.Write(ie.Stack())
+	}
 }
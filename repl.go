@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/peterh/liner"
+
+	"github.com/macrat/tako-lang/tako"
+)
+
+const (
+	prompt         = "tako> "
+	continuePrompt = "  ... "
+)
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func historyPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".tako_history")
+}
+
+func isWordChar(r byte) bool {
+	return r == '_' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9'
+}
+
+func completer(in *tako.Interpreter) liner.WordCompleter {
+	return func(line string, pos int) (string, []string, string) {
+		start := pos
+		for start > 0 && isWordChar(line[start-1]) {
+			start--
+		}
+		head, word, tail := line[:start], line[start:pos], line[pos:]
+
+		candidates := in.Names()
+		if strings.HasSuffix(head, ".") {
+			candidates = tako.MethodNames()
+		}
+
+		var completions []string
+		for _, c := range candidates {
+			if strings.HasPrefix(c, word) && isWordChar(c[0]) {
+				completions = append(completions, c)
+			}
+		}
+
+		return head, completions, tail
+	}
+}
+
+func repl(in *tako.Interpreter) {
+	line := liner.NewLiner()
+	defer line.Close()
+
+	line.SetCtrlCAborts(true)
+	line.SetWordCompleter(completer(in))
+
+	history := historyPath()
+	if history != "" {
+		if f, err := os.Open(history); err == nil {
+			line.ReadHistory(f)
+			f.Close()
+		}
+	}
+
+	var lines []string
+	for {
+		p := prompt
+		if len(lines) > 0 {
+			p = continuePrompt
+		}
+
+		input, err := line.Prompt(p)
+		if err == liner.ErrPromptAborted {
+			lines = nil
+			continue
+		} else if err == io.EOF {
+			fmt.Println()
+			break
+		} else if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			break
+		}
+
+		if strings.TrimSpace(input) != "" {
+			line.AppendHistory(input)
+		}
+
+		lines = append(lines, input)
+		src := strings.Join(lines, "\n")
+		if tako.IsIncomplete(src) {
+			continue
+		}
+		lines = nil
+
+		if strings.TrimSpace(src) == "" {
+			continue
+		}
+
+		result, err := in.EvalReader(strings.NewReader(src), "<stdin>")
+		if err != nil {
+			printError(err)
+			continue
+		}
+
+		fmt.Println(result)
+	}
+
+	if history != "" {
+		if f, err := os.Create(history); err == nil {
+			line.WriteHistory(f)
+			f.Close()
+		}
+	}
+}
@@ -0,0 +1,500 @@
+package tako
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Definition struct {
+	Name  Identifier
+	Value Expression
+	Scope *Scope
+	Body  *Scope
+
+	order    int
+	branch   []branch
+	assigned bool
+}
+
+type branch struct {
+	condition int
+	arm       int
+}
+
+type Scope struct {
+	Parent      *Scope
+	Children    []*Scope
+	Definitions []*Definition
+	Start       Position
+	End         Position
+
+	names    map[string]*Definition
+	located  bool
+	function bool
+}
+
+func newScope(parent *Scope) *Scope {
+	s := &Scope{
+		Parent: parent,
+		names:  make(map[string]*Definition),
+	}
+	if parent != nil {
+		parent.Children = append(parent.Children, s)
+	}
+	return s
+}
+
+func (s *Scope) define(ident Identifier, value Expression) *Definition {
+	if d, ok := s.names[ident.Key]; ok {
+		return d
+	}
+
+	d := &Definition{
+		Name:  ident,
+		Value: value,
+		Scope: s,
+		order: -1,
+	}
+	s.names[ident.Key] = d
+	s.Definitions = append(s.Definitions, d)
+
+	return d
+}
+
+func (s *Scope) extend(pos Position) {
+	for cur := s; cur != nil; cur = cur.Parent {
+		if !cur.located || positionBefore(pos, cur.Start) {
+			cur.Start = pos
+		}
+		if !cur.located || positionBefore(cur.End, pos) {
+			cur.End = pos
+		}
+		cur.located = true
+	}
+}
+
+func (s *Scope) Lookup(name string) *Definition {
+	for cur := s; cur != nil; cur = cur.Parent {
+		if d, ok := cur.names[name]; ok {
+			return d
+		}
+	}
+	return nil
+}
+
+func (s *Scope) Names() []string {
+	seen := make(map[string]bool)
+	var names []string
+
+	for cur := s; cur != nil; cur = cur.Parent {
+		for _, d := range cur.Definitions {
+			if !seen[d.Name.Key] {
+				seen[d.Name.Key] = true
+				names = append(names, d.Name.Key)
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (s *Scope) contains(pos Position) bool {
+	return s.located && !positionBefore(pos, s.Start) && (pos.Line <= s.End.Line)
+}
+
+type Reference struct {
+	Name       Identifier
+	Definition *Definition
+
+	scope *Scope
+	order int
+	site  bool
+}
+
+type scopedCall struct {
+	site  callSite
+	scope *Scope
+}
+
+type Analysis struct {
+	Root       *Scope
+	References []Reference
+
+	order         int
+	branch        []branch
+	conditions    int
+	redefinitions []Identifier
+	calls         []scopedCall
+}
+
+func Analyze(expr Expression) *Analysis {
+	a := &Analysis{
+		Root: newScope(nil),
+	}
+
+	a.collectDefinitions(a.Root, expr)
+	a.analyze(a.Root, expr)
+
+	return a
+}
+
+func (a *Analysis) ScopeAt(pos Position) *Scope {
+	scope := a.Root
+
+	for {
+		var next *Scope
+		for _, c := range scope.Children {
+			if c.contains(pos) {
+				next = c
+			}
+		}
+		if next == nil {
+			return scope
+		}
+		scope = next
+	}
+}
+
+func (a *Analysis) ReferenceAt(pos Position) (Reference, bool) {
+	for _, r := range a.References {
+		p := r.Name.Pos
+		if p.Line == pos.Line && p.Column <= pos.Column && pos.Column <= p.Column+len(r.Name.Key) {
+			return r, true
+		}
+	}
+	return Reference{}, false
+}
+
+func (a *Analysis) reference(scope *Scope, ident Identifier) {
+	if ident.Pos.Filename == "builtin" {
+		return
+	}
+
+	scope.extend(ident.Pos)
+	a.References = append(a.References, Reference{
+		Name:       ident,
+		Definition: scope.Lookup(ident.Key),
+		scope:      scope,
+		order:      a.order,
+	})
+}
+
+func (a *Analysis) declare(scope *Scope, ident Identifier) {
+	d := scope.names[ident.Key]
+	if d == nil {
+		d = scope.define(ident, nil)
+	} else if d.Name != ident {
+		a.redefinitions = append(a.redefinitions, ident)
+	}
+	a.effect(d)
+
+	a.reference(scope, ident)
+	a.References[len(a.References)-1].site = true
+}
+
+func (a *Analysis) effect(d *Definition) {
+	if d.order < 0 {
+		a.order++
+		d.order = a.order
+	}
+}
+
+func exclusive(x, y []branch) bool {
+	for _, bx := range x {
+		for _, by := range y {
+			if bx.condition == by.condition && bx.arm != by.arm {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (a *Analysis) collectDefinitions(scope *Scope, expr Expression) {
+	switch e := expr.(type) {
+	case ExpressionList:
+		for _, x := range e {
+			a.collectDefinitions(scope, x)
+		}
+
+	case Condition:
+		a.collectDefinitions(scope, e.Condition)
+
+		a.conditions++
+		id := a.conditions
+		a.branch = append(a.branch, branch{id, 0})
+		a.collectDefinitions(scope, e.Then)
+		if e.Else != nil {
+			a.branch[len(a.branch)-1].arm = 1
+			a.collectDefinitions(scope, e.Else)
+		}
+		a.branch = a.branch[:len(a.branch)-1]
+
+	case Try:
+		a.collectDefinitions(scope, e.Body)
+
+	case Logical:
+		if e.Left != nil {
+			a.collectDefinitions(scope, e.Left)
+		}
+		a.collectDefinitions(scope, e.Right)
+
+	case Interpolation:
+		for _, x := range e.Parts {
+			a.collectDefinitions(scope, x)
+		}
+
+	case While:
+		a.collectDefinitions(scope, e.Condition)
+
+	case For:
+		a.collectDefinitions(scope, e.Iterable)
+
+	case Break:
+		if e.Value != nil {
+			a.collectDefinitions(scope, e.Value)
+		}
+
+	case Return:
+		if e.Value != nil {
+			a.collectDefinitions(scope, e.Value)
+		}
+
+	case *Object:
+		for _, x := range e.Indexed {
+			a.collectDefinitions(scope, x)
+		}
+		for _, x := range e.Named {
+			a.collectDefinitions(scope, x)
+		}
+
+	case FunctionCall:
+		if ident, ok := e.Function.(Identifier); ok && ident.Key == "::=:" && len(e.Arguments) == 2 {
+			if target, ok := e.Arguments[0].(Identifier); ok {
+				if d, ok := scope.names[target.Key]; !ok {
+					d = scope.define(target, e.Arguments[1])
+					d.branch = append([]branch{}, a.branch...)
+				} else if !exclusive(d.branch, a.branch) {
+					a.redefinitions = append(a.redefinitions, target)
+				}
+			}
+		}
+
+		a.collectDefinitions(scope, e.Function)
+		for _, x := range e.Arguments {
+			a.collectDefinitions(scope, x)
+		}
+	}
+}
+
+func (a *Analysis) analyze(scope *Scope, expr Expression) {
+	switch e := expr.(type) {
+	case Identifier:
+		a.reference(scope, e)
+
+	case ExpressionList:
+		for _, x := range e {
+			a.analyze(scope, x)
+		}
+
+	case Condition:
+		a.analyze(scope, e.Condition)
+		a.analyze(scope, e.Then)
+		if e.Else != nil {
+			a.analyze(scope, e.Else)
+		}
+
+	case Try:
+		a.analyze(scope, e.Body)
+
+		catch := newScope(scope)
+		a.declare(catch, e.Variable)
+		a.collectDefinitions(catch, e.Catch)
+		a.analyze(catch, e.Catch)
+
+	case Logical:
+		if e.Left != nil {
+			a.analyze(scope, e.Left)
+		}
+		a.analyze(scope, e.Right)
+
+	case Interpolation:
+		for _, x := range e.Parts {
+			a.analyze(scope, x)
+		}
+
+	case While:
+		a.analyze(scope, e.Condition)
+
+		body := newScope(scope)
+		a.collectDefinitions(body, e.Body)
+		a.analyze(body, e.Body)
+
+	case For:
+		a.analyze(scope, e.Iterable)
+
+		body := newScope(scope)
+		a.declare(body, e.Variable)
+		a.collectDefinitions(body, e.Body)
+		a.analyze(body, e.Body)
+
+	case Break:
+		if e.Value != nil {
+			a.analyze(scope, e.Value)
+		}
+
+	case Return:
+		if e.Value != nil {
+			a.analyze(scope, e.Value)
+		}
+
+	case *Object:
+		for _, x := range e.Indexed {
+			a.analyze(scope, x)
+		}
+		for _, x := range e.Named {
+			a.analyze(scope, x)
+		}
+
+	case FunctionDefine:
+		a.analyzeFunction(scope, e)
+
+	case FunctionCall:
+		a.analyze(scope, e.Function)
+
+		ident, ok := e.Function.(Identifier)
+
+		if ok && ident.Key == "::=:" && len(e.Arguments) == 2 {
+			if target, ok := e.Arguments[0].(Identifier); ok {
+				d, ok := scope.names[target.Key]
+				if !ok {
+					d = scope.define(target, e.Arguments[1])
+				}
+
+				if fd, ok := e.Arguments[1].(FunctionDefine); ok {
+					body := a.analyzeFunction(scope, fd)
+					if d.Name == target {
+						d.Body = body
+					}
+				} else {
+					a.analyze(scope, e.Arguments[1])
+				}
+
+				if d.Name == target {
+					a.effect(d)
+				}
+				a.reference(scope, target)
+				a.References[len(a.References)-1].site = true
+				return
+			}
+		}
+
+		if ok && ident.Key == ":=:" && len(e.Arguments) == 2 {
+			if target, ok := e.Arguments[0].(Identifier); ok {
+				if d := scope.Lookup(target.Key); d != nil {
+					d.assigned = true
+				}
+			}
+		}
+
+		if _, isDefine := e.Function.(FunctionDefine); isDefine || ok && ident.Pos.Filename != "builtin" {
+			site := callSite{
+				function: e.Function,
+				argc:     len(e.Arguments),
+				pos:      e.Pos,
+				end:      e.End,
+			}
+			if ok {
+				site.name = ident.String()
+			}
+			a.calls = append(a.calls, scopedCall{site, scope})
+		}
+
+		for i, x := range e.Arguments {
+			if ok && quotedMemberArguments[ident.Key] && i == 1 {
+				continue
+			}
+			a.analyze(scope, x)
+		}
+	}
+}
+
+func (a *Analysis) analyzeFunction(parent *Scope, fd FunctionDefine) *Scope {
+	scope := newScope(parent)
+	scope.function = true
+
+	for _, arg := range fd.Arguments {
+		a.declare(scope, arg)
+	}
+	if fd.VariableArgument != nil {
+		a.declare(scope, *fd.VariableArgument)
+	}
+
+	a.collectDefinitions(scope, fd.Expression)
+	a.analyze(scope, fd.Expression)
+
+	return scope
+}
+
+func Signature(name string, value Expression) string {
+	f, ok := value.(Function)
+	if !ok {
+		return fmt.Sprintf("%s := %s", name, value)
+	}
+
+	var args []string
+	if bf, ok := f.(BuiltInFunction); ok {
+		args = append(args, bf.names...)
+	} else {
+		for _, a := range f.GetArguments() {
+			args = append(args, a.Key)
+		}
+	}
+	if va := f.GetVariableArgument(); va != nil {
+		args = append(args, va.Key+"...")
+	}
+
+	return fmt.Sprintf("%s(%s)", name, strings.Join(args, ", "))
+}
+
+func Help(value Expression) string {
+	var name, doc string
+	switch f := value.(type) {
+	case FunctionDefine:
+		name, doc = f.name, f.Doc
+	case *Closure:
+		name, doc = f.proto.Define.name, f.proto.Define.Doc
+	case BuiltInFunction:
+		name, doc = f.name, "builtin function"
+	default:
+		return fmt.Sprintf("no documentation for %s", value)
+	}
+
+	s := Signature(name, value)
+	if doc != "" {
+		s += "\n\n" + doc
+	}
+	return s
+}
+
+func BuiltinNames() []string {
+	return builtinContext.Names()
+}
+
+func GetBuiltin(name string) (Expression, bool) {
+	v, ok := builtinContext.values[name]
+	if !ok {
+		v, ok = builtinMethods[name]
+	}
+	if !ok {
+		v, ok = stringMethods[name]
+	}
+	return v, ok
+}
+
+func positionBefore(a, b Position) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+}
@@ -0,0 +1,271 @@
+package tako
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Expression interface {
+	Compute(Context) (Expression, error)
+	Computable(Context) bool
+}
+
+type LocatedExpression interface {
+	Expression
+
+	Position() Position
+}
+
+type ExpressionList []Expression
+
+func (el ExpressionList) String() string {
+	ss := make([]string, len(el))
+	for i, e := range el {
+		ss[i] = fmt.Sprint(e)
+	}
+	return strings.Join(ss, "; ")
+}
+
+func (el ExpressionList) Compute(ctx Context) (exp Expression, err error) {
+	if len(el) == 0 {
+		return Null{}, nil
+	}
+
+	for _, e := range el[:len(el)-1] {
+		if _, err = ctx.ComputeRecursive(e); err != nil {
+			return
+		}
+	}
+
+	return NewTailCall(ctx, el[len(el)-1]), nil
+}
+
+func (el ExpressionList) Computable(ctx Context) bool {
+	return true
+}
+
+type TailCall struct {
+	Expression Expression
+	Context    Context
+
+	function bool
+}
+
+func NewTailCall(ctx Context, expr Expression) Expression {
+	if !expr.Computable(ctx) {
+		return expr
+	}
+
+	return TailCall{
+		Expression: expr,
+		Context:    ctx,
+	}
+}
+
+func (tc TailCall) String() string {
+	return fmt.Sprint(tc.Expression)
+}
+
+func (tc TailCall) Compute(ctx Context) (Expression, error) {
+	result, err := tc.Expression.Compute(tc.Context)
+	if err != nil {
+		return nil, NewTracedError(err, tc.Context.frame)
+	}
+	return result, nil
+}
+
+func (tc TailCall) Computable(ctx Context) bool {
+	return true
+}
+
+type Literal struct {
+	Value Expression
+	Pos   Position
+	End   Position
+
+	raw string
+}
+
+func (l Literal) String() string {
+	return fmt.Sprint(l.Value)
+}
+
+func (l Literal) Compute(ctx Context) (Expression, error) {
+	return l.Value, nil
+}
+
+func (l Literal) Computable(ctx Context) bool {
+	return true
+}
+
+func (l Literal) Position() Position {
+	return l.Pos
+}
+
+type Token struct {
+	Token   int
+	Literal string
+	Pos     Position
+	End     Position
+}
+
+type Identifier struct {
+	Key string
+	Pos Position
+}
+
+func NewIdentifier(key string) Identifier {
+	return Identifier{
+		Key: key,
+		Pos: Position{Filename: "builtin"},
+	}
+}
+
+func (i Identifier) String() string {
+	return i.Key
+}
+
+func (i Identifier) Compute(ctx Context) (Expression, error) {
+	if val, err := ctx.Get(i); err != nil {
+		return nil, err
+	} else {
+		return val, nil
+	}
+}
+
+func (i Identifier) Computable(ctx Context) bool {
+	return true
+}
+
+func (i Identifier) Position() Position {
+	return i.Pos
+}
+
+func spanOf(expr Expression) (start, end Position, ok bool) {
+	switch e := expr.(type) {
+	case Identifier:
+		return e.Pos, e.Pos.advance(e.Key), e.Pos.Filename != "builtin"
+
+	case Literal:
+		return e.Pos, e.End, true
+
+	case FunctionCall:
+		return e.Pos, e.End, e.End != (Position{})
+
+	case FunctionDefine:
+		return e.Pos, e.End, e.End != (Position{})
+
+	case Condition:
+		return e.Pos, e.End, e.End != (Position{})
+
+	case Try:
+		return e.Pos, e.End, e.End != (Position{})
+
+	case Logical:
+		return e.Pos, e.End, e.End != (Position{})
+
+	case Interpolation:
+		return e.Pos, e.End, true
+
+	case While:
+		return e.Pos, e.End, e.End != (Position{})
+
+	case For:
+		return e.Pos, e.End, e.End != (Position{})
+
+	case Break:
+		return e.Pos, e.End, e.End != (Position{})
+
+	case Continue:
+		return e.Pos, e.End, e.End != (Position{})
+
+	case Return:
+		return e.Pos, e.End, e.End != (Position{})
+
+	case *Object:
+		return e.pos, e.end, e.end != (Position{})
+
+	case ExpressionList:
+		if len(e) == 0 {
+			return Position{}, Position{}, false
+		}
+
+		start, _, ok := spanOf(e[0])
+		if !ok {
+			return Position{}, Position{}, false
+		}
+
+		_, end, ok := spanOf(e[len(e)-1])
+		return start, end, ok
+	}
+
+	return Position{}, Position{}, false
+}
+
+func startOf(expr Expression) Position {
+	start, _, _ := spanOf(expr)
+	return start
+}
+
+func endOf(expr Expression) Position {
+	_, end, _ := spanOf(expr)
+	return end
+}
+
+func withSpan(expr Expression, start, end Position) Expression {
+	switch e := expr.(type) {
+	case Literal:
+		e.Pos, e.End = start, end
+		return e
+
+	case FunctionCall:
+		e.Pos, e.End = start, end
+		return e
+
+	case FunctionDefine:
+		e.Pos, e.End = start, end
+		return e
+
+	case Condition:
+		e.Pos, e.End = start, end
+		return e
+
+	case Try:
+		e.Pos, e.End = start, end
+		return e
+
+	case Logical:
+		e.Pos, e.End = start, end
+		return e
+
+	case Interpolation:
+		e.Pos, e.End = start, end
+		return e
+
+	case While:
+		e.Pos, e.End = start, end
+		return e
+
+	case For:
+		e.Pos, e.End = start, end
+		return e
+
+	case Break:
+		e.Pos, e.End = start, end
+		return e
+
+	case Continue:
+		e.Pos, e.End = start, end
+		return e
+
+	case Return:
+		e.Pos, e.End = start, end
+		return e
+
+	case *Object:
+		e.pos, e.end = start, end
+		return e
+	}
+
+	return expr
+}
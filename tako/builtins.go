@@ -0,0 +1,947 @@
+package tako
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	builtinContext = Context{
+		values: map[string]Expression{
+			"null":  Null{},
+			"true":  Boolean(true),
+			"false": Boolean(false),
+
+			":+:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				x, err := ctx.ComputeRecursive(args["x"])
+				if err != nil {
+					return nil, err
+				}
+
+				y, err := ctx.ComputeRecursive(args["y"])
+				if err != nil {
+					return nil, err
+				}
+
+				switch a := x.(type) {
+				case Integer, BigInt, Float, Decimal:
+					value, err := calculate(ctx, "+", x, y)
+					return value, locate(ctx, err)
+
+				case String:
+					if b, ok := y.(String); ok {
+						return a + b, nil
+					}
+					return nil, typeError(ctx, "right operand of +", y, "string")
+				}
+
+				return nil, typeError(ctx, "left operand of +", x, "number", "string")
+			}, "", "x", "y"),
+
+			":-:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				x, err := ctx.ComputeRecursive(args["x"])
+				if err != nil {
+					return nil, err
+				}
+
+				y, err := ctx.ComputeRecursive(args["y"])
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := calculate(ctx, "-", x, y)
+				return value, locate(ctx, err)
+			}, "", "x", "y"),
+
+			":*:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				x, err := ctx.ComputeRecursive(args["x"])
+				if err != nil {
+					return nil, err
+				}
+
+				y, err := ctx.ComputeRecursive(args["y"])
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := calculate(ctx, "*", x, y)
+				return value, locate(ctx, err)
+			}, "", "x", "y"),
+
+			":/:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				x, err := ctx.ComputeRecursive(args["x"])
+				if err != nil {
+					return nil, err
+				}
+
+				y, err := ctx.ComputeRecursive(args["y"])
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := calculate(ctx, "/", x, y)
+				return value, locate(ctx, err)
+			}, "", "x", "y"),
+
+			":%:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				x, err := ctx.ComputeRecursive(args["x"])
+				if err != nil {
+					return nil, err
+				}
+
+				y, err := ctx.ComputeRecursive(args["y"])
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := calculate(ctx, "%", x, y)
+				return value, locate(ctx, err)
+			}, "", "x", "y"),
+
+			":^:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				x, err := ctx.ComputeRecursive(args["x"])
+				if err != nil {
+					return nil, err
+				}
+
+				y, err := ctx.ComputeRecursive(args["y"])
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := calculate(ctx, "^", x, y)
+				return value, locate(ctx, err)
+			}, "", "x", "y"),
+
+			":div:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				x, err := ctx.ComputeRecursive(args["x"])
+				if err != nil {
+					return nil, err
+				}
+
+				y, err := ctx.ComputeRecursive(args["y"])
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := calculate(ctx, "div", x, y)
+				return value, locate(ctx, err)
+			}, "", "x", "y"),
+
+			":&:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				x, err := ctx.ComputeRecursive(args["x"])
+				if err != nil {
+					return nil, err
+				}
+
+				y, err := ctx.ComputeRecursive(args["y"])
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := calculate(ctx, "&", x, y)
+				return value, locate(ctx, err)
+			}, "", "x", "y"),
+
+			":|:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				x, err := ctx.ComputeRecursive(args["x"])
+				if err != nil {
+					return nil, err
+				}
+
+				y, err := ctx.ComputeRecursive(args["y"])
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := calculate(ctx, "|", x, y)
+				return value, locate(ctx, err)
+			}, "", "x", "y"),
+
+			":xor:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				x, err := ctx.ComputeRecursive(args["x"])
+				if err != nil {
+					return nil, err
+				}
+
+				y, err := ctx.ComputeRecursive(args["y"])
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := calculate(ctx, "xor", x, y)
+				return value, locate(ctx, err)
+			}, "", "x", "y"),
+
+			":<<:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				x, err := ctx.ComputeRecursive(args["x"])
+				if err != nil {
+					return nil, err
+				}
+
+				y, err := ctx.ComputeRecursive(args["y"])
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := calculate(ctx, "<<", x, y)
+				return value, locate(ctx, err)
+			}, "", "x", "y"),
+
+			":>>:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				x, err := ctx.ComputeRecursive(args["x"])
+				if err != nil {
+					return nil, err
+				}
+
+				y, err := ctx.ComputeRecursive(args["y"])
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := calculate(ctx, ">>", x, y)
+				return value, locate(ctx, err)
+			}, "", "x", "y"),
+
+			"-:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				x, err := ctx.ComputeRecursive(args["x"])
+				if err != nil {
+					return nil, err
+				}
+
+				if n, ok := negate(x); ok {
+					return n, nil
+				}
+
+				return nil, typeError(ctx, "operand of -", x, "number")
+			}, "", "x"),
+
+			"!:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				x, err := ctx.ComputeRecursive(args["x"])
+				if err != nil {
+					return nil, err
+				}
+
+				b, ok := x.(Boolean)
+				if !ok {
+					return nil, typeError(ctx, "operand of !", x, "boolean")
+				}
+
+				return !b, nil
+			}, "", "x"),
+
+			":==:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				x, err := ctx.ComputeRecursive(args["x"])
+				if err != nil {
+					return nil, err
+				}
+
+				y, err := ctx.ComputeRecursive(args["y"])
+				if err != nil {
+					return nil, err
+				}
+
+				if err := decimalMix("==", x, y); err != nil {
+					return nil, locate(ctx, err)
+				}
+
+				return Boolean(equals(x, y)), nil
+			}, "", "x", "y"),
+
+			":!=:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				x, err := ctx.ComputeRecursive(args["x"])
+				if err != nil {
+					return nil, err
+				}
+
+				y, err := ctx.ComputeRecursive(args["y"])
+				if err != nil {
+					return nil, err
+				}
+
+				if err := decimalMix("!=", x, y); err != nil {
+					return nil, locate(ctx, err)
+				}
+
+				return Boolean(!equals(x, y)), nil
+			}, "", "x", "y"),
+
+			":<:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				x, err := ctx.ComputeRecursive(args["x"])
+				if err != nil {
+					return nil, err
+				}
+
+				y, err := ctx.ComputeRecursive(args["y"])
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := calculate(ctx, "<", x, y)
+				return value, locate(ctx, err)
+			}, "", "x", "y"),
+
+			":<=:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				x, err := ctx.ComputeRecursive(args["x"])
+				if err != nil {
+					return nil, err
+				}
+
+				y, err := ctx.ComputeRecursive(args["y"])
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := calculate(ctx, "<=", x, y)
+				return value, locate(ctx, err)
+			}, "", "x", "y"),
+
+			":>:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				x, err := ctx.ComputeRecursive(args["x"])
+				if err != nil {
+					return nil, err
+				}
+
+				y, err := ctx.ComputeRecursive(args["y"])
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := calculate(ctx, ">", x, y)
+				return value, locate(ctx, err)
+			}, "", "x", "y"),
+
+			":>=:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				x, err := ctx.ComputeRecursive(args["x"])
+				if err != nil {
+					return nil, err
+				}
+
+				y, err := ctx.ComputeRecursive(args["y"])
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := calculate(ctx, ">=", x, y)
+				return value, locate(ctx, err)
+			}, "", "x", "y"),
+
+			":=:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				value, err := ctx.ComputeRecursive(args["expression"])
+				if err != nil {
+					return nil, err
+				}
+
+				identifier, err := identifierArgument(ctx, "name of =", args["identifier"])
+				if err != nil {
+					return nil, err
+				}
+
+				return value, ctx.Put(identifier, value)
+			}, "", "identifier", "expression"),
+
+			"::=:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				value, err := ctx.ComputeRecursive(args["expression"])
+				if err != nil {
+					return nil, err
+				}
+
+				identifier, err := identifierArgument(ctx, "name of :=", args["identifier"])
+				if err != nil {
+					return nil, err
+				}
+
+				return value, ctx.Define(identifier, value)
+			}, "", "identifier", "expression"),
+
+			":.:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				object, err := ctx.ComputeRecursive(args["object"])
+				if err != nil {
+					return nil, err
+				}
+
+				identifier, err := identifierArgument(ctx, "member of .", args["identifier"])
+				if err != nil {
+					return nil, err
+				}
+
+				switch obj := object.(type) {
+				case *Object:
+					value, err := obj.Get(identifier)
+					return value, locate(ctx, err)
+				case String:
+					if method, ok := stringMethods[identifier.Key]; ok {
+						return method, nil
+					}
+					return nil, NotDefinedError(identifier)
+				}
+
+				return nil, typeError(ctx, "target of .", object, "object", "string")
+			}, "", "object", "identifier"),
+
+			":[]:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				object, err := ctx.ComputeRecursive(args["object"])
+				if err != nil {
+					return nil, err
+				}
+
+				index, err := ctx.ComputeRecursive(args["index"])
+				if err != nil {
+					return nil, err
+				}
+
+				obj, err := objectArgument(ctx, "target of []", object)
+				if err != nil {
+					return nil, err
+				}
+
+				switch index.(type) {
+				case Integer, BigInt, Float, String:
+					value, err := obj.Get(index)
+					return value, locate(ctx, err)
+				}
+
+				return nil, typeError(ctx, "index of object", index, "number", "string")
+			}, "", "object", "index"),
+
+			":.=:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				object, err := ctx.ComputeRecursive(args["object"])
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := ctx.ComputeRecursive(args["value"])
+				if err != nil {
+					return nil, err
+				}
+
+				obj, err := objectArgument(ctx, "target of .", object)
+				if err != nil {
+					return nil, err
+				}
+
+				identifier, err := identifierArgument(ctx, "member of .", args["identifier"])
+				if err != nil {
+					return nil, err
+				}
+
+				if _, ok := obj.Named[identifier.Key]; ok {
+					obj.Named[identifier.Key] = value
+					return value, nil
+				}
+
+				return nil, NotDefinedError(identifier)
+			}, "", "object", "identifier", "value"),
+
+			":.:=:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				object, err := ctx.ComputeRecursive(args["object"])
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := ctx.ComputeRecursive(args["value"])
+				if err != nil {
+					return nil, err
+				}
+
+				obj, err := objectArgument(ctx, "target of .", object)
+				if err != nil {
+					return nil, err
+				}
+
+				identifier, err := identifierArgument(ctx, "member of .", args["identifier"])
+				if err != nil {
+					return nil, err
+				}
+
+				if _, ok := obj.Named[identifier.Key]; ok {
+					return nil, AlreadyDefinedError(identifier)
+				}
+
+				obj.Named[identifier.Key] = value
+				return value, nil
+			}, "", "object", "identifier", "value"),
+
+			":[]=:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				object, err := ctx.ComputeRecursive(args["object"])
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := ctx.ComputeRecursive(args["value"])
+				if err != nil {
+					return nil, err
+				}
+
+				obj, err := objectArgument(ctx, "target of []", object)
+				if err != nil {
+					return nil, err
+				}
+
+				i, err := ctx.ComputeRecursive(args["index"])
+				if err != nil {
+					return nil, err
+				}
+
+				if key, ok := i.(String); ok {
+					if _, ok := obj.Named[string(key)]; ok {
+						obj.Named[string(key)] = value
+						return value, nil
+					}
+					return nil, locate(ctx, KeyError{key: string(key)})
+				}
+
+				index, ok := toIndex(i)
+				if !ok {
+					return nil, typeError(ctx, "index of object", i, "integer", "string")
+				}
+
+				if 0 <= index && index < len(obj.Indexed) {
+					obj.Indexed[index] = value
+					return value, nil
+				}
+
+				max := len(obj.Indexed) - 1
+				if max < 0 {
+					max = 0
+				}
+
+				return nil, OutOfBoundsError{
+					max: max,
+					got: i,
+					pos: ctx.callPosition(),
+					end: ctx.callEnd(),
+				}
+			}, "", "object", "index", "value"),
+
+			":[]:=:": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				object, err := ctx.ComputeRecursive(args["object"])
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := ctx.ComputeRecursive(args["value"])
+				if err != nil {
+					return nil, err
+				}
+
+				obj, err := objectArgument(ctx, "target of []", object)
+				if err != nil {
+					return nil, err
+				}
+
+				index, err := ctx.ComputeRecursive(args["index"])
+				if err != nil {
+					return nil, err
+				}
+
+				key, ok := index.(String)
+				if !ok {
+					return nil, typeError(ctx, "index of object", index, "string")
+				}
+
+				if _, ok := obj.Named[string(key)]; ok {
+					return nil, AlreadyDefinedError(Identifier{Key: string(key), Pos: ctx.callPosition()})
+				}
+
+				obj.Named[string(key)] = value
+				return value, nil
+			}, "", "object", "index", "value"),
+
+			"integer": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				value, err := ctx.ComputeRecursive(args["value"])
+				if err != nil {
+					return nil, err
+				}
+
+				switch x := value.(type) {
+				case Integer, BigInt:
+					return x, nil
+
+				case Float:
+					if math.IsNaN(float64(x)) || math.IsInf(float64(x), 0) {
+						break
+					}
+					n, _ := big.NewFloat(math.Trunc(float64(x))).Int(nil)
+					return normalize(n), nil
+
+				case Decimal:
+					return normalize(new(big.Int).Quo(x.value, pow10(x.scale))), nil
+
+				case String:
+					n, ok := parseNumberString(string(x))
+					if !ok || !isNumber(n, true) {
+						break
+					}
+					return n, nil
+
+				default:
+					return nil, typeError(ctx, "value of integer", value, "number", "string")
+				}
+
+				return nil, ConversionError{value: value, to: "integer", pos: ctx.callPosition(), end: ctx.callEnd()}
+			}, "", "value"),
+
+			"float": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				value, err := ctx.ComputeRecursive(args["value"])
+				if err != nil {
+					return nil, err
+				}
+
+				switch x := value.(type) {
+				case Integer, BigInt, Float, Decimal:
+					return toFloat(x), nil
+
+				case String:
+					if n, ok := parseNumberString(string(x)); ok {
+						return toFloat(n), nil
+					}
+					return nil, ConversionError{value: value, to: "float", pos: ctx.callPosition(), end: ctx.callEnd()}
+				}
+
+				return nil, typeError(ctx, "value of float", value, "number", "string")
+			}, "", "value"),
+
+			"decimal": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				value, err := ctx.ComputeRecursive(args["value"])
+				if err != nil {
+					return nil, err
+				}
+
+				switch x := value.(type) {
+				case Integer, BigInt, Decimal:
+					return toDecimal(x), nil
+
+				case Float:
+					if d, err := ParseDecimal(strconv.FormatFloat(float64(x), 'g', -1, 64)); err == nil {
+						return d, nil
+					}
+
+				case String:
+					if d, err := ParseDecimal(string(x)); err == nil {
+						return d, nil
+					}
+
+				default:
+					return nil, typeError(ctx, "value of decimal", value, "number", "string")
+				}
+
+				return nil, ConversionError{value: value, to: "decimal", pos: ctx.callPosition(), end: ctx.callEnd()}
+			}, "", "value"),
+
+			"decimal_precision": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				value, err := ctx.ComputeRecursive(args["digits"])
+				if err != nil {
+					return nil, err
+				}
+
+				n, ok := value.(Integer)
+				if !ok {
+					return nil, typeError(ctx, "digits of decimal_precision", value, "integer")
+				}
+				if n < 1 {
+					return nil, ArithmeticError{reason: "decimal precision must be positive", pos: ctx.callPosition(), end: ctx.callEnd()}
+				}
+
+				if ctx.interpreter == nil {
+					return nil, ArithmeticError{reason: "decimal settings are not available here", pos: ctx.callPosition(), end: ctx.callEnd()}
+				}
+
+				previous := ctx.interpreter.DecimalPrecision
+				ctx.interpreter.DecimalPrecision = int(n)
+				return Integer(previous), nil
+			}, "", "digits"),
+
+			"decimal_rounding": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				value, err := ctx.ComputeRecursive(args["mode"])
+				if err != nil {
+					return nil, err
+				}
+
+				mode, ok := value.(String)
+				if !ok {
+					return nil, typeError(ctx, "mode of decimal_rounding", value, "string")
+				}
+				if !decimalRoundings[string(mode)] {
+					return nil, ArithmeticError{reason: "unknown rounding mode " + mode.String(), pos: ctx.callPosition(), end: ctx.callEnd()}
+				}
+
+				if ctx.interpreter == nil {
+					return nil, ArithmeticError{reason: "decimal settings are not available here", pos: ctx.callPosition(), end: ctx.callEnd()}
+				}
+
+				previous := ctx.interpreter.DecimalRounding
+				ctx.interpreter.DecimalRounding = string(mode)
+				return String(previous), nil
+			}, "", "mode"),
+
+			"string": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				value, err := ctx.ComputeRecursive(args["value"])
+				if err != nil {
+					return nil, err
+				}
+
+				return String(display(value)), nil
+			}, "", "value"),
+
+			"throw": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				value, err := ctx.ComputeRecursive(args["value"])
+				if err != nil {
+					return nil, err
+				}
+
+				return nil, ThrownError{value: value}
+			}, "", "value"),
+
+			"help": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				value, err := ctx.ComputeRecursive(args["value"])
+				if err != nil {
+					return nil, err
+				}
+
+				s := Help(value)
+
+				fmt.Fprintln(ctx.stdout(), s)
+
+				return Null{}, nil
+			}, "", "value"),
+
+			"print": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				a_, err := ctx.ComputeRecursive(variables)
+				if err != nil {
+					return nil, err
+				}
+
+				as, err := objectArgument(ctx, "arguments of print", a_)
+				if err != nil {
+					return nil, err
+				}
+
+				ss := make([]string, len(as.Indexed))
+				for i, x := range as.Indexed {
+					ss[i] = display(x)
+				}
+
+				s := strings.Join(ss, " ")
+
+				fmt.Fprint(ctx.stdout(), s)
+
+				return String(s), nil
+			}, "args"),
+
+			"println": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+				a_, err := ctx.ComputeRecursive(variables)
+				if err != nil {
+					return nil, err
+				}
+
+				as, err := objectArgument(ctx, "arguments of println", a_)
+				if err != nil {
+					return nil, err
+				}
+
+				ss := make([]string, len(as.Indexed))
+				for i, x := range as.Indexed {
+					ss[i] = display(x)
+				}
+
+				s := strings.Join(ss, " ")
+
+				fmt.Fprintln(ctx.stdout(), s)
+
+				return String(s + "\n"), nil
+			}, "args"),
+		},
+	}
+)
+
+type BuiltInFunction struct {
+	Arguments []Identifier
+	Function  func(Context, *Object, map[string]Expression) (Expression, error)
+	Variables string
+
+	names []string
+	name  string
+}
+
+func init() {
+	builtinContext.values["import"] = NewBuiltInFunction(importFunction, "", "path")
+
+	for k, v := range builtinContext.values {
+		if bf, ok := v.(BuiltInFunction); ok {
+			bf.name = k
+			builtinContext.values[k] = bf
+		}
+	}
+
+	for k, v := range builtinMethods {
+		if bf, ok := v.(BuiltInFunction); ok {
+			bf.name = k
+			builtinMethods[k] = bf
+		}
+	}
+
+	for k, v := range stringMethods {
+		if bf, ok := v.(BuiltInFunction); ok {
+			bf.name = k
+			stringMethods[k] = bf
+		}
+	}
+}
+
+func locate(ctx Context, err error) error {
+	switch e := err.(type) {
+	case OutOfBoundsError:
+		if e.pos == (Position{}) {
+			e.pos, e.end = ctx.callPosition(), ctx.callEnd()
+		}
+		return e
+	case TypeError:
+		if e.pos == (Position{}) {
+			e.pos, e.end = ctx.callPosition(), ctx.callEnd()
+		}
+		return e
+	case ArithmeticError:
+		if e.pos == (Position{}) {
+			e.pos, e.end = ctx.callPosition(), ctx.callEnd()
+		}
+		return e
+	case KeyError:
+		if e.pos == (Position{}) {
+			e.pos, e.end = ctx.callPosition(), ctx.callEnd()
+		}
+		return e
+	}
+	return err
+}
+
+func typeError(ctx Context, name string, value Expression, excepts ...string) TypeError {
+	return TypeError{
+		name:    name,
+		excepts: excepts,
+		got:     typeName(value),
+		pos:     ctx.callPosition(),
+		end:     ctx.callEnd(),
+	}
+}
+
+func identifierArgument(ctx Context, name string, value Expression) (Identifier, error) {
+	identifier, ok := value.(Identifier)
+	if !ok {
+		return Identifier{}, typeError(ctx, name, value, "identifier")
+	}
+	return identifier, nil
+}
+
+func objectArgument(ctx Context, name string, value Expression) (*Object, error) {
+	obj, ok := value.(*Object)
+	if !ok {
+		return nil, typeError(ctx, name, value, "object")
+	}
+	return obj, nil
+}
+
+func equals(x, y Expression) bool {
+	switch a := x.(type) {
+	case FunctionDefine:
+		b, ok := y.(FunctionDefine)
+		return ok && a.Pos == b.Pos && a.scope == b.scope
+
+	case BuiltInFunction:
+		b, ok := y.(BuiltInFunction)
+		return ok && a.name != "" && a.name == b.name
+
+	case Integer:
+		switch b := y.(type) {
+		case Float:
+			return Float(a) == b
+		case Decimal:
+			return equals(b, a)
+		}
+
+	case BigInt:
+		switch b := y.(type) {
+		case BigInt:
+			return a.value.Cmp(b.value) == 0
+		case Float:
+			return toFloat(a) == b
+		case Decimal:
+			return equals(b, a)
+		}
+		return false
+
+	case Float:
+		switch y.(type) {
+		case Integer, BigInt:
+			return a == toFloat(y)
+		}
+
+	case Decimal:
+		switch b := y.(type) {
+		case Integer, BigInt, Decimal:
+			scale := a.scale
+			if d := toDecimal(b); d.scale > scale {
+				scale = d.scale
+			}
+			return a.rescale(scale).Cmp(toDecimal(b).rescale(scale)) == 0
+		}
+		return false
+	}
+
+	if x == nil || y == nil || !reflect.TypeOf(x).Comparable() || !reflect.TypeOf(y).Comparable() {
+		return false
+	}
+	return x == y
+}
+
+func NewBuiltInFunction(fun func(Context, *Object, map[string]Expression) (Expression, error), variables string, arguments ...string) BuiltInFunction {
+	args := make([]Identifier, len(arguments))
+	for i, a := range arguments {
+		args[i] = NewIdentifier("__builtin_functions_argument_" + a + "__")
+	}
+
+	return BuiltInFunction{
+		Arguments: args,
+		Function:  fun,
+		Variables: variables,
+		names:     arguments,
+	}
+}
+
+func (bf BuiltInFunction) Compute(ctx Context) (Expression, error) {
+	return bf, nil
+}
+
+func (bf BuiltInFunction) Computable(ctx Context) bool {
+	return false
+}
+
+func (bf BuiltInFunction) Position() Position {
+	return Position{Filename: "builtin"}
+}
+
+func (bf BuiltInFunction) Call(ctx Context, args map[Identifier]Expression, variables *Object) (Expression, error) {
+	as := make(map[string]Expression, len(bf.names))
+	for i, a := range bf.Arguments {
+		as[bf.names[i]] = args[a]
+	}
+
+	return bf.Function(ctx, variables, as)
+}
+
+func (bf BuiltInFunction) GetArguments() []Identifier {
+	return bf.Arguments
+}
+
+func (bf BuiltInFunction) GetVariableArgument() *Identifier {
+	if bf.Variables != "" {
+		i := NewIdentifier(bf.Variables)
+		return &i
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,50 @@
+package tako
+
+import (
+	"testing"
+)
+
+func TestBuiltinTypeErrors(t *testing.T) {
+	testEvalError(t, []evalTest{
+		{src: `1 - "a"`, output: "TypeError: right operand of - must be number but got string"},
+		{src: `"a" * 2`, output: "TypeError: left operand of * must be number but got string"},
+		{src: `"a" + 1`, output: "TypeError: right operand of + must be string but got integer"},
+		{src: `-"a"`, output: "TypeError: operand of - must be number but got string"},
+		{src: `1 < "a"`, output: "TypeError: right operand of < must be number but got string"},
+		{src: `5.length()`, output: "TypeError: target of . must be object or string but got integer"},
+		{src: `integer([1])`, output: "TypeError: value of integer must be number or string but got object"},
+	})
+}
+
+func TestBuiltinArgumentTypes(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments []Expression
+		output    string
+	}{
+		{":=:", []Expression{Integer(1), Integer(2)}, "TypeError: name of = must be identifier but got integer"},
+		{"::=:", []Expression{String("x"), Integer(2)}, "TypeError: name of := must be identifier but got string"},
+		{":.:", []Expression{NewObject(), Integer(1)}, "TypeError: member of . must be identifier but got integer"},
+		{":.=:", []Expression{NewObject(), Integer(1), Integer(2)}, "TypeError: member of . must be identifier but got integer"},
+		{":.:=:", []Expression{NewObject(), Integer(1), Integer(2)}, "TypeError: member of . must be identifier but got integer"},
+	}
+
+	for _, tt := range tests {
+		f, ok := GetBuiltin(tt.name)
+		if !ok {
+			t.Fatalf("%s is not a builtin", tt.name)
+		}
+
+		for _, e := range engines {
+			_, err := e.interpreter().Call(f, tt.arguments...)
+			if err == nil {
+				t.Errorf("%s (%s): expected %s but got no error", tt.name, e.name, tt.output)
+				continue
+			}
+
+			if output := errorString(err); output != tt.output {
+				t.Errorf("%s (%s): expected %s but got %s", tt.name, e.name, tt.output, output)
+			}
+		}
+	}
+}
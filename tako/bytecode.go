@@ -0,0 +1,179 @@
+package tako
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Opcode byte
+
+const (
+	OpConst Opcode = iota
+	OpLoad
+	OpDefine
+	OpPut
+	OpPop
+	OpJump
+	OpJumpIfFalse
+	OpClosure
+	OpObject
+	OpConcat
+	OpCall
+	OpTailCall
+	OpBinary
+	OpUnary
+	OpTry
+	OpEndTry
+	OpIter
+	OpNext
+	OpLoop
+	OpCollect
+	OpBreak
+	OpLeave
+	OpReturn
+)
+
+var opcodeNames = []string{
+	OpConst:       "CONST",
+	OpLoad:        "LOAD",
+	OpDefine:      "DEFINE",
+	OpPut:         "PUT",
+	OpPop:         "POP",
+	OpJump:        "JUMP",
+	OpJumpIfFalse: "JUMP_IF_FALSE",
+	OpClosure:     "CLOSURE",
+	OpObject:      "OBJECT",
+	OpConcat:      "CONCAT",
+	OpCall:        "CALL",
+	OpTailCall:    "TAIL_CALL",
+	OpBinary:      "BINARY",
+	OpUnary:       "UNARY",
+	OpTry:         "TRY",
+	OpEndTry:      "END_TRY",
+	OpIter:        "ITER",
+	OpNext:        "NEXT",
+	OpLoop:        "LOOP",
+	OpCollect:     "COLLECT",
+	OpBreak:       "BREAK",
+	OpLeave:       "LEAVE",
+	OpReturn:      "RETURN",
+}
+
+func (op Opcode) String() string {
+	if int(op) < len(opcodeNames) {
+		return opcodeNames[op]
+	}
+	return fmt.Sprintf("OP(%d)", byte(op))
+}
+
+type Instruction struct {
+	Op Opcode
+	A  int
+	B  int
+}
+
+func (i Instruction) String() string {
+	return fmt.Sprintf("%-13s %d %d", i.Op, i.A, i.B)
+}
+
+type variableSlot struct {
+	depth int
+	index int
+}
+
+type variableReference struct {
+	ident      Identifier
+	candidates []variableSlot
+}
+
+type callSite struct {
+	function Expression
+	name     string
+	argc     int
+	pos      Position
+	end      Position
+	method   bool
+}
+
+type objectShape struct {
+	indexed int
+	named   []string
+}
+
+type Proto struct {
+	Define FunctionDefine
+	Code   []Instruction
+
+	constants  []Expression
+	variables  []variableReference
+	calls      []callSite
+	objects    []objectShape
+	conditions []Condition
+	loops      []For
+	protos     []*Proto
+	slots      int
+	slotNames  []Identifier
+}
+
+func (p *Proto) String() string {
+	lines := make([]string, len(p.Code))
+	for i, c := range p.Code {
+		lines[i] = fmt.Sprintf("%04d %s", i, c)
+	}
+	return strings.Join(lines, "\n")
+}
+
+type Closure struct {
+	proto *Proto
+	scope *vmScope
+	ctx   Context
+}
+
+func (c *Closure) String() string {
+	return c.proto.Define.String()
+}
+
+func (c *Closure) Compute(ctx Context) (Expression, error) {
+	return c, nil
+}
+
+func (c *Closure) Computable(ctx Context) bool {
+	return false
+}
+
+func (c *Closure) Position() Position {
+	return c.proto.Define.Pos
+}
+
+func (c *Closure) GetArguments() []Identifier {
+	return c.proto.Define.Arguments
+}
+
+func (c *Closure) GetVariableArgument() *Identifier {
+	return c.proto.Define.VariableArgument
+}
+
+func (c *Closure) Call(ctx Context, args map[Identifier]Expression, variables *Object) (Expression, error) {
+	values := make([]Expression, len(c.proto.Define.Arguments))
+	for i, a := range c.proto.Define.Arguments {
+		v, err := ctx.ComputeRecursive(args[a])
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+
+	if c.proto.Define.VariableArgument != nil {
+		vo, err := ctx.ComputeRecursive(variables)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, vo.(*Object).Indexed...)
+	}
+
+	vm := NewVM(c.ctx)
+	vm.ctx.frame = ctx.frame
+	vm.ctx.call = ctx.calleeFrame()
+
+	return vm.call(c, values)
+}
@@ -0,0 +1,66 @@
+package tako
+
+import (
+	"sort"
+)
+
+func (a *Analysis) Check() []LocatedError {
+	var errs []LocatedError
+
+	for _, r := range a.References {
+		if r.site {
+			continue
+		}
+
+		if r.Definition == nil {
+			if _, ok := GetBuiltin(r.Name.Key); !ok {
+				errs = append(errs, NotDefinedError(r.Name))
+			}
+		} else if r.order < r.Definition.order && immediate(r.scope, r.Definition.Scope) {
+			errs = append(errs, NotDefinedError(r.Name))
+		}
+	}
+
+	for _, ident := range a.redefinitions {
+		errs = append(errs, AlreadyDefinedError(ident))
+	}
+
+	for _, c := range a.calls {
+		var f Function
+
+		switch fn := c.site.function.(type) {
+		case FunctionDefine:
+			f = fn
+
+		case Identifier:
+			if d := c.scope.Lookup(fn.Key); d != nil {
+				if !d.assigned {
+					f, _ = d.Value.(Function)
+				}
+			} else if v, ok := GetBuiltin(fn.Key); ok {
+				f, _ = v.(Function)
+			}
+		}
+
+		if f != nil {
+			if err := checkArguments(c.site, f); err != nil {
+				errs = append(errs, err.(LocatedError))
+			}
+		}
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		return positionBefore(errs[i].Position(), errs[j].Position())
+	})
+
+	return errs
+}
+
+func immediate(from, to *Scope) bool {
+	for s := from; s != to; s = s.Parent {
+		if s == nil || s.function {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package tako
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		src      string
+		problems []string
+	}{
+		{"x := 1\ny := z + x", []string{"test:2:6: z is not defined"}},
+		{"y := w\nw := 1", []string{"test:1:6: w is not defined"}},
+		{"f := (){ q }", []string{"test:1:10: q is not defined"}},
+		{"x := 1\nx := 2", []string{"test:2:1: x is already defined"}},
+		{"f := (a, b){ a + b }\nf(1)", []string{"test:2:1: f excepted 2 arguments but got 1 arguments"}},
+		{"x := 1\nf := (){ x := 2; x }\nf(1, 2)", []string{"test:3:1: f excepted 0 arguments but got 2 arguments"}},
+		{"g := (){ h() }\nh := (){ 1 }\ng()", nil},
+		{"print(1, 2)\nlen := 1", nil},
+	}
+
+	for _, tt := range tests {
+		errs, expr := Parse(strings.NewReader(tt.src), "test")
+		if len(errs) > 0 {
+			t.Errorf("%q: %s", tt.src, errs[0])
+			continue
+		}
+
+		var problems []string
+		for _, p := range Analyze(expr).Check() {
+			problems = append(problems, p.Position().String()+": "+p.Message())
+		}
+		if strings.Join(problems, "\n") != strings.Join(tt.problems, "\n") {
+			t.Errorf("%q: expected %q but got %q", tt.src, tt.problems, problems)
+		}
+	}
+}
@@ -0,0 +1,550 @@
+package tako
+
+import (
+	"fmt"
+	"sort"
+)
+
+var (
+	binaryOperators = map[string]bool{
+		":+:": true, ":-:": true, ":*:": true, ":/:": true, ":div:": true, ":%:": true, ":^:": true,
+		":&:": true, ":|:": true, ":xor:": true, ":<<:": true, ":>>:": true,
+		":<:": true, ":<=:": true, ":>:": true, ":>=:": true, ":==:": true, ":!=:": true,
+	}
+
+	unaryOperators = map[string]bool{
+		"-:": true, "!:": true,
+	}
+
+	quotedMemberArguments = map[string]bool{
+		":.:": true, ":.=:": true, ":.:=:": true,
+	}
+)
+
+type CompileError struct {
+	expr Expression
+}
+
+func (e CompileError) Error() string {
+	if le, ok := e.expr.(LocatedExpression); ok {
+		return fmt.Sprintf("%s: can not compile %s", le.Position(), e.expr)
+	}
+	return fmt.Sprintf("can not compile %s", e.expr)
+}
+
+type compiler struct {
+	proto  *Proto
+	names  map[string]int
+	parent *compiler
+}
+
+func Compile(expr Expression) (*Proto, error) {
+	c := &compiler{
+		proto: &Proto{},
+	}
+
+	if err := c.compile(expr, false); err != nil {
+		return nil, err
+	}
+	c.emit(OpReturn, 0, 0)
+
+	return c.proto, nil
+}
+
+func compileFunction(parent *compiler, fd FunctionDefine) (*Proto, error) {
+	c := &compiler{
+		proto:  &Proto{Define: fd},
+		names:  make(map[string]int),
+		parent: parent,
+	}
+
+	for _, a := range fd.Arguments {
+		c.declare(a)
+	}
+	if fd.VariableArgument != nil {
+		c.declare(*fd.VariableArgument)
+	}
+	c.collectDefinitions(fd.Expression)
+
+	if err := c.compile(fd.Expression, true); err != nil {
+		return nil, err
+	}
+	c.emit(OpReturn, 0, 0)
+
+	return c.proto, nil
+}
+
+func (c *compiler) declare(ident Identifier) {
+	if _, ok := c.names[ident.Key]; ok {
+		return
+	}
+
+	c.names[ident.Key] = c.proto.slots
+	c.proto.slotNames = append(c.proto.slotNames, ident)
+	c.proto.slots++
+}
+
+func (c *compiler) collectDefinitions(expr Expression) {
+	switch e := expr.(type) {
+	case ExpressionList:
+		for _, x := range e {
+			c.collectDefinitions(x)
+		}
+
+	case Condition:
+		c.collectDefinitions(e.Condition)
+		c.collectDefinitions(e.Then)
+		if e.Else != nil {
+			c.collectDefinitions(e.Else)
+		}
+
+	case Try:
+		c.collectDefinitions(e.Body)
+
+	case Logical:
+		if e.Left != nil {
+			c.collectDefinitions(e.Left)
+		}
+		c.collectDefinitions(e.Right)
+
+	case Interpolation:
+		for _, x := range e.Parts {
+			c.collectDefinitions(x)
+		}
+
+	case While:
+		c.collectDefinitions(e.Condition)
+
+	case For:
+		c.collectDefinitions(e.Iterable)
+
+	case Break:
+		if e.Value != nil {
+			c.collectDefinitions(e.Value)
+		}
+
+	case Return:
+		if e.Value != nil {
+			c.collectDefinitions(e.Value)
+		}
+
+	case *Object:
+		for _, x := range e.Indexed {
+			c.collectDefinitions(x)
+		}
+		for _, x := range e.Named {
+			c.collectDefinitions(x)
+		}
+
+	case FunctionCall:
+		if ident, ok := e.Function.(Identifier); ok && ident.Key == "::=:" && len(e.Arguments) == 2 {
+			if target, ok := e.Arguments[0].(Identifier); ok {
+				c.declare(target)
+			}
+		}
+
+		c.collectDefinitions(e.Function)
+		for _, x := range e.Arguments {
+			c.collectDefinitions(x)
+		}
+	}
+}
+
+func (c *compiler) isLocal(key string) bool {
+	for cur := c; cur != nil; cur = cur.parent {
+		if _, ok := cur.names[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *compiler) emit(op Opcode, a, b int) int {
+	c.proto.Code = append(c.proto.Code, Instruction{Op: op, A: a, B: b})
+	return len(c.proto.Code) - 1
+}
+
+func (c *compiler) constant(expr Expression) int {
+	c.proto.constants = append(c.proto.constants, expr)
+	return len(c.proto.constants) - 1
+}
+
+func (c *compiler) variable(ident Identifier) int {
+	ref := variableReference{ident: ident}
+
+	depth := 0
+	for cur := c; cur != nil && cur.names != nil; cur = cur.parent {
+		if i, ok := cur.names[ident.Key]; ok {
+			ref.candidates = append(ref.candidates, variableSlot{depth: depth, index: i})
+		}
+		depth++
+	}
+
+	c.proto.variables = append(c.proto.variables, ref)
+	return len(c.proto.variables) - 1
+}
+
+func (c *compiler) call(fc FunctionCall, name string) int {
+	c.proto.calls = append(c.proto.calls, callSite{
+		function: fc.Function,
+		name:     name,
+		argc:     len(fc.Arguments),
+		pos:      fc.Pos,
+		end:      fc.End,
+		method:   fc.method,
+	})
+	return len(c.proto.calls) - 1
+}
+
+func (c *compiler) compile(expr Expression, tail bool) error {
+	switch e := expr.(type) {
+	case Integer, BigInt, Float, Decimal, String, Boolean, Null:
+		c.emit(OpConst, c.constant(e), 0)
+
+	case Literal:
+		c.emit(OpConst, c.constant(e.Value), 0)
+
+	case Identifier:
+		c.emit(OpLoad, c.variable(e), 0)
+
+	case ExpressionList:
+		if len(e) == 0 {
+			c.emit(OpConst, c.constant(Null{}), 0)
+			return nil
+		}
+
+		for i, x := range e {
+			last := i == len(e)-1
+			if err := c.compile(x, tail && last); err != nil {
+				return err
+			}
+			if !last {
+				c.emit(OpPop, 0, 0)
+			}
+		}
+
+	case Condition:
+		return c.compileCondition(e, tail)
+
+	case FunctionDefine:
+		proto, err := compileFunction(c, e)
+		if err != nil {
+			return err
+		}
+		c.proto.protos = append(c.proto.protos, proto)
+		c.emit(OpClosure, len(c.proto.protos)-1, 0)
+
+	case *Object:
+		shape := objectShape{indexed: len(e.Indexed)}
+		for k := range e.Named {
+			shape.named = append(shape.named, k)
+		}
+		sort.Strings(shape.named)
+
+		for _, x := range e.Indexed {
+			if err := c.compile(x, false); err != nil {
+				return err
+			}
+		}
+		for _, k := range shape.named {
+			if err := c.compile(e.Named[k], false); err != nil {
+				return err
+			}
+		}
+
+		c.proto.objects = append(c.proto.objects, shape)
+		c.emit(OpObject, len(c.proto.objects)-1, 0)
+
+	case Interpolation:
+		for _, x := range e.Parts {
+			if err := c.compile(x, false); err != nil {
+				return err
+			}
+		}
+		c.emit(OpConcat, len(e.Parts), 0)
+
+	case FunctionCall:
+		return c.compileCall(e, tail)
+
+	case Try:
+		return c.compileTry(e)
+
+	case Logical:
+		return c.compileLogical(e)
+
+	case While:
+		return c.compileWhile(e)
+
+	case For:
+		return c.compileFor(e)
+
+	case Break:
+		if e.Value == nil {
+			c.emit(OpBreak, 1, 0)
+			return nil
+		}
+		if err := c.compile(e.Value, false); err != nil {
+			return err
+		}
+		c.emit(OpBreak, 2, 0)
+
+	case Continue:
+		c.emit(OpBreak, 0, 0)
+
+	case Return:
+		if e.Value == nil {
+			c.emit(OpConst, c.constant(Null{}), 0)
+		} else if err := c.compile(e.Value, false); err != nil {
+			return err
+		}
+		c.emit(OpLeave, 0, 0)
+
+	default:
+		return CompileError{expr: expr}
+	}
+
+	return nil
+}
+
+func (c *compiler) compileCondition(cond Condition, tail bool) error {
+	if err := c.compile(cond.Condition, false); err != nil {
+		return err
+	}
+
+	c.proto.conditions = append(c.proto.conditions, cond)
+	jumpElse := c.emit(OpJumpIfFalse, 0, len(c.proto.conditions)-1)
+
+	if err := c.compile(cond.Then, tail); err != nil {
+		return err
+	}
+	jumpEnd := c.emit(OpJump, 0, 0)
+
+	c.proto.Code[jumpElse].A = len(c.proto.Code)
+	if cond.Else != nil {
+		if err := c.compile(cond.Else, tail); err != nil {
+			return err
+		}
+	} else {
+		c.emit(OpConst, c.constant(Null{}), 0)
+	}
+
+	c.proto.Code[jumpEnd].A = len(c.proto.Code)
+
+	return nil
+}
+
+func (c *compiler) test(l Logical, operand Expression) (int, error) {
+	if err := c.compile(operand, false); err != nil {
+		return 0, err
+	}
+
+	c.proto.conditions = append(c.proto.conditions, Condition{Condition: operand, Pos: l.Pos, End: l.End})
+	return c.emit(OpJumpIfFalse, 0, len(c.proto.conditions)-1), nil
+}
+
+func (c *compiler) compileLogical(l Logical) error {
+	var jumps []int
+
+	if l.Left != nil && l.Operator == "||" {
+		jumpRight, err := c.test(l, l.Left)
+		if err != nil {
+			return err
+		}
+		c.emit(OpConst, c.constant(Boolean(true)), 0)
+		jumps = append(jumps, c.emit(OpJump, 0, 0))
+		c.proto.Code[jumpRight].A = len(c.proto.Code)
+	}
+
+	var falses []int
+	if l.Left != nil && l.Operator == "&&" {
+		jumpFalse, err := c.test(l, l.Left)
+		if err != nil {
+			return err
+		}
+		falses = append(falses, jumpFalse)
+	}
+
+	jumpFalse, err := c.test(l, l.Right)
+	if err != nil {
+		return err
+	}
+	falses = append(falses, jumpFalse)
+
+	result := Boolean(l.Left != nil)
+	c.emit(OpConst, c.constant(result), 0)
+	jumps = append(jumps, c.emit(OpJump, 0, 0))
+
+	for _, j := range falses {
+		c.proto.Code[j].A = len(c.proto.Code)
+	}
+	c.emit(OpConst, c.constant(!result), 0)
+
+	for _, j := range jumps {
+		c.proto.Code[j].A = len(c.proto.Code)
+	}
+
+	return nil
+}
+
+func (c *compiler) loopBody(args []Identifier, body Expression, pos Position) (int, error) {
+	proto, err := compileFunction(c, FunctionDefine{
+		Arguments:  args,
+		Expression: body,
+		Pos:        pos,
+	})
+	if err != nil {
+		return 0, err
+	}
+	c.proto.protos = append(c.proto.protos, proto)
+
+	return len(c.proto.protos) - 1, nil
+}
+
+func (c *compiler) compileWhile(w While) error {
+	body, err := c.loopBody([]Identifier{}, w.Body, w.Pos)
+	if err != nil {
+		return err
+	}
+
+	c.proto.objects = append(c.proto.objects, objectShape{})
+	c.emit(OpObject, len(c.proto.objects)-1, 0)
+
+	head := len(c.proto.Code)
+	if err := c.compile(w.Condition, false); err != nil {
+		return err
+	}
+	c.proto.conditions = append(c.proto.conditions, Condition{Condition: w.Condition, Pos: w.Pos, End: w.End})
+	jumpExit := c.emit(OpJumpIfFalse, 0, len(c.proto.conditions)-1)
+
+	c.emit(OpLoop, body, 0)
+	collect := c.emit(OpCollect, 0, 1)
+	c.emit(OpJump, head, 0)
+
+	c.proto.Code[jumpExit].A = len(c.proto.Code)
+	c.proto.Code[collect].A = len(c.proto.Code)
+
+	return nil
+}
+
+func (c *compiler) compileFor(f For) error {
+	body, err := c.loopBody([]Identifier{f.Variable}, f.Body, f.Pos)
+	if err != nil {
+		return err
+	}
+
+	if err := c.compile(f.Iterable, false); err != nil {
+		return err
+	}
+	c.proto.loops = append(c.proto.loops, f)
+	c.emit(OpIter, len(c.proto.loops)-1, 0)
+
+	head := c.emit(OpNext, 0, 0)
+	c.emit(OpLoop, body, 1)
+	collect := c.emit(OpCollect, 0, 2)
+	c.emit(OpJump, head, 0)
+
+	c.proto.Code[head].A = len(c.proto.Code)
+	c.proto.Code[collect].A = len(c.proto.Code)
+	c.emit(OpPop, 0, 0)
+
+	return nil
+}
+
+func (c *compiler) compileTry(t Try) error {
+	proto, err := compileFunction(c, FunctionDefine{
+		Arguments:  []Identifier{t.Variable},
+		Expression: t.Catch,
+		Pos:        t.Pos,
+	})
+	if err != nil {
+		return err
+	}
+	c.proto.protos = append(c.proto.protos, proto)
+
+	start := c.emit(OpTry, len(c.proto.protos)-1, 0)
+
+	if err := c.compile(t.Body, false); err != nil {
+		return err
+	}
+	c.emit(OpEndTry, 0, 0)
+
+	c.proto.Code[start].B = len(c.proto.Code)
+
+	return nil
+}
+
+func (c *compiler) compileCall(fc FunctionCall, tail bool) error {
+	ident, isIdent := fc.Function.(Identifier)
+	special := isIdent && !c.isLocal(ident.Key)
+
+	name := ""
+	if isIdent {
+		name = ident.Key
+	}
+
+	if special {
+		switch {
+		case ident.Key == "::=:" || ident.Key == ":=:":
+			if len(fc.Arguments) != 2 {
+				return CompileError{expr: fc}
+			}
+			target, ok := fc.Arguments[0].(Identifier)
+			if !ok {
+				return CompileError{expr: fc}
+			}
+			if !c.isLocal(target.Key) && (binaryOperators[target.Key] || unaryOperators[target.Key]) {
+				return CompileError{expr: fc}
+			}
+
+			if err := c.compile(fc.Arguments[1], false); err != nil {
+				return err
+			}
+
+			if ident.Key == "::=:" {
+				c.emit(OpDefine, c.variable(target), 0)
+			} else {
+				c.emit(OpPut, c.variable(target), 0)
+			}
+			return nil
+
+		case binaryOperators[ident.Key] && len(fc.Arguments) == 2:
+			for _, x := range fc.Arguments {
+				if err := c.compile(x, false); err != nil {
+					return err
+				}
+			}
+			c.emit(OpBinary, c.call(fc, name), 0)
+			return nil
+
+		case unaryOperators[ident.Key] && len(fc.Arguments) == 1:
+			if err := c.compile(fc.Arguments[0], false); err != nil {
+				return err
+			}
+			c.emit(OpUnary, c.call(fc, name), 0)
+			return nil
+		}
+	}
+
+	if err := c.compile(fc.Function, false); err != nil {
+		return err
+	}
+
+	for i, x := range fc.Arguments {
+		if special && quotedMemberArguments[ident.Key] && i == 1 {
+			if _, ok := x.(Identifier); !ok {
+				return CompileError{expr: fc}
+			}
+			c.emit(OpConst, c.constant(x), 0)
+		} else if err := c.compile(x, false); err != nil {
+			return err
+		}
+	}
+
+	if tail && c.names != nil {
+		c.emit(OpTailCall, c.call(fc, name), 0)
+	} else {
+		c.emit(OpCall, c.call(fc, name), 0)
+	}
+
+	return nil
+}
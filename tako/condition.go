@@ -1,4 +1,4 @@
-package main
+package tako
 
 import "fmt"
 
@@ -7,6 +7,16 @@ type Condition struct {
 	Then      Expression
 	Else      Expression
 	Pos       Position
+	End       Position
+
+	thenEnd Position
+	elsePos Position
+}
+
+type conditionBlock struct {
+	body  Expression
+	start Position
+	end   Position
 }
 
 func (c Condition) String() string {
@@ -25,7 +35,7 @@ func (c Condition) Compute(ctx Context) (Expression, error) {
 
 	var expr Expression
 	if b, ok := cond.(Boolean); !ok {
-		return nil, ConditionTypeError{pos: c.Position()}
+		return nil, c.typeError()
 	} else if b {
 		expr = c.Then
 	} else {
@@ -36,7 +46,7 @@ func (c Condition) Compute(ctx Context) (Expression, error) {
 		return Null{}, nil
 	}
 
-	return ctx.ComputeRecursive(expr)
+	return NewTailCall(ctx, expr), nil
 }
 
 func (c Condition) Computable(ctx Context) bool {
@@ -46,3 +56,10 @@ func (c Condition) Computable(ctx Context) bool {
 func (c Condition) Position() Position {
 	return c.Pos
 }
+
+func (c Condition) typeError() ConditionTypeError {
+	if start, end, ok := spanOf(c.Condition); ok {
+		return ConditionTypeError{pos: start, end: end}
+	}
+	return ConditionTypeError{pos: c.Pos, end: c.End}
+}
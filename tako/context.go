@@ -0,0 +1,149 @@
+package tako
+
+import "sort"
+
+type Context struct {
+	parent *Context
+	values map[string]Expression
+
+	frame *StackFrame
+	call  *StackFrame
+	tail  bool
+
+	interpreter *Interpreter
+}
+
+func NewContext() Context {
+	return builtinContext.MakeScope()
+}
+
+func (c Context) GetByString(key string) (Expression, error) {
+	return c.Get(NewIdentifier(key))
+}
+
+func (c Context) Get(key Identifier) (Expression, error) {
+	if v, ok := c.values[key.Key]; ok {
+		return v, nil
+	}
+
+	for cur := &c; cur != nil; cur = cur.parent {
+		if val, ok := cur.values[key.Key]; ok {
+			return val, nil
+		}
+	}
+
+	return nil, NotDefinedError(key)
+}
+
+func (c Context) Put(key Identifier, value Expression) error {
+	for cur := &c; cur != nil; cur = cur.parent {
+		if _, ok := cur.values[key.Key]; ok {
+			cur.values[key.Key] = value
+			return nil
+		}
+	}
+
+	return NotDefinedError(key)
+}
+
+func (c Context) Define(key Identifier, value Expression) error {
+	if _, ok := c.values[key.Key]; ok {
+		return AlreadyDefinedError(key)
+	}
+
+	c.values[key.Key] = value
+
+	return nil
+}
+
+func (c Context) Names() []string {
+	seen := make(map[string]bool)
+	var names []string
+
+	for cur := &c; cur != nil; cur = cur.parent {
+		for k := range cur.values {
+			if !seen[k] {
+				seen[k] = true
+				names = append(names, k)
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (c Context) ComputeRecursive(expr Expression) (result Expression, err error) {
+	c.tail = false
+
+	entered := false
+
+	r := expr
+	for r.Computable(c) {
+		if tc, ok := r.(TailCall); ok && tc.function {
+			entered = true
+		}
+
+		r, err = r.Compute(c)
+		if s, ok := err.(returnSignal); ok && entered {
+			r = s.value
+		} else if err != nil {
+			return nil, NewTracedError(err, c.frame)
+		}
+	}
+	return r, nil
+}
+
+func (c Context) MakeScope() Context {
+	return Context{
+		parent: &c,
+		values: make(map[string]Expression),
+		frame:  c.frame,
+		tail:   c.tail,
+
+		interpreter: c.interpreter,
+	}
+}
+
+func (c Context) pushFrame(name string, pos, end Position) Context {
+	c.call = &StackFrame{
+		Name:   name,
+		Pos:    pos,
+		End:    end,
+		parent: c.frame,
+	}
+
+	return c
+}
+
+func (c Context) calleeFrame() *StackFrame {
+	if c.call == nil {
+		return &StackFrame{parent: c.frame}
+	}
+
+	if c.tail && c.frame != nil && c.call.parent == c.frame {
+		return &StackFrame{
+			Name:   c.call.Name,
+			Pos:    c.frame.Pos,
+			End:    c.frame.End,
+			parent: c.frame.parent,
+		}
+	}
+
+	return c.call
+}
+
+func (c Context) callPosition() Position {
+	if c.call != nil {
+		return c.call.Pos
+	}
+	return Position{Filename: "builtin"}
+}
+
+func (c Context) callEnd() Position {
+	if c.call != nil {
+		return c.call.End
+	}
+	return Position{Filename: "builtin"}
+}
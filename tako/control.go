@@ -0,0 +1,82 @@
+package tako
+
+func misplacedControls(expr Expression, inLoop, inFunction bool) []SyntaxError {
+	var errs []SyntaxError
+
+	switch e := expr.(type) {
+	case ExpressionList:
+		for _, x := range e {
+			errs = append(errs, misplacedControls(x, inLoop, inFunction)...)
+		}
+
+	case Condition:
+		errs = append(errs, misplacedControls(e.Condition, inLoop, inFunction)...)
+		errs = append(errs, misplacedControls(e.Then, inLoop, inFunction)...)
+		if e.Else != nil {
+			errs = append(errs, misplacedControls(e.Else, inLoop, inFunction)...)
+		}
+
+	case Try:
+		errs = append(errs, misplacedControls(e.Body, inLoop, inFunction)...)
+		errs = append(errs, misplacedControls(e.Catch, inLoop, inFunction)...)
+
+	case Logical:
+		if e.Left != nil {
+			errs = append(errs, misplacedControls(e.Left, inLoop, inFunction)...)
+		}
+		errs = append(errs, misplacedControls(e.Right, inLoop, inFunction)...)
+
+	case Interpolation:
+		for _, x := range e.Parts {
+			errs = append(errs, misplacedControls(x, inLoop, inFunction)...)
+		}
+
+	case *Object:
+		for _, x := range e.Indexed {
+			errs = append(errs, misplacedControls(x, inLoop, inFunction)...)
+		}
+		for _, x := range e.Named {
+			errs = append(errs, misplacedControls(x, inLoop, inFunction)...)
+		}
+
+	case FunctionCall:
+		errs = append(errs, misplacedControls(e.Function, inLoop, inFunction)...)
+		for _, x := range e.Arguments {
+			errs = append(errs, misplacedControls(x, inLoop, inFunction)...)
+		}
+
+	case FunctionDefine:
+		errs = append(errs, misplacedControls(e.Expression, false, true)...)
+
+	case While:
+		errs = append(errs, misplacedControls(e.Condition, inLoop, inFunction)...)
+		errs = append(errs, misplacedControls(e.Body, true, inFunction)...)
+
+	case For:
+		errs = append(errs, misplacedControls(e.Iterable, inLoop, inFunction)...)
+		errs = append(errs, misplacedControls(e.Body, true, inFunction)...)
+
+	case Break:
+		if !inLoop {
+			errs = append(errs, SyntaxError{pos: e.Pos, literal: "break", line: e.Pos.SourceLine()})
+		}
+		if e.Value != nil {
+			errs = append(errs, misplacedControls(e.Value, inLoop, inFunction)...)
+		}
+
+	case Continue:
+		if !inLoop {
+			errs = append(errs, SyntaxError{pos: e.Pos, literal: "continue", line: e.Pos.SourceLine()})
+		}
+
+	case Return:
+		if !inFunction {
+			errs = append(errs, SyntaxError{pos: e.Pos, literal: "return", line: e.Pos.SourceLine()})
+		}
+		if e.Value != nil {
+			errs = append(errs, misplacedControls(e.Value, inLoop, inFunction)...)
+		}
+	}
+
+	return errs
+}
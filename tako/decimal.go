@@ -0,0 +1,265 @@
+package tako
+
+import (
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultDecimalPrecision = 28
+	defaultDecimalRounding  = "half_even"
+	decimalExponentLimit    = 1 << 20
+)
+
+var (
+	decimalRoundings = map[string]bool{
+		"half_even": true, "half_up": true, "half_down": true,
+		"up": true, "down": true, "ceiling": true, "floor": true,
+	}
+
+	decimalString = regexp.MustCompile(`^([+-]?)([0-9](?:_?[0-9])*)(?:\.([0-9](?:_?[0-9])*))?(?:[eE]([+-]?[0-9]+))?$`)
+)
+
+type Decimal struct {
+	value *big.Int
+	scale int
+}
+
+func NewDecimal(value *big.Int, scale int) Decimal {
+	d := Decimal{value: new(big.Int).Set(value), scale: scale}
+	if scale < 0 {
+		d.value.Mul(d.value, pow10(-scale))
+		d.scale = 0
+	}
+	return d
+}
+
+func ParseDecimal(s string) (Decimal, error) {
+	m := decimalString.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Decimal{}, strconv.ErrSyntax
+	}
+
+	digits := strings.Replace(m[2]+m[3], "_", "", -1)
+	scale := len(strings.Replace(m[3], "_", "", -1))
+
+	if m[4] != "" {
+		exp, err := strconv.Atoi(m[4])
+		if err != nil {
+			return Decimal{}, err
+		}
+		if exp > decimalExponentLimit || exp < -decimalExponentLimit {
+			return Decimal{}, strconv.ErrRange
+		}
+		scale -= exp
+	}
+
+	value, _ := new(big.Int).SetString(m[1]+digits, 10)
+	return NewDecimal(value, scale), nil
+}
+
+func (d Decimal) String() string {
+	s := new(big.Int).Abs(d.value).String()
+
+	if d.scale > 0 {
+		if len(s) <= d.scale {
+			s = strings.Repeat("0", d.scale-len(s)+1) + s
+		}
+		s = s[:len(s)-d.scale] + "." + s[len(s)-d.scale:]
+	}
+
+	if d.value.Sign() < 0 {
+		return "-" + s
+	}
+	return s
+}
+
+func (d Decimal) Compute(ctx Context) (Expression, error) {
+	return d, nil
+}
+
+func (d Decimal) Computable(ctx Context) bool {
+	return false
+}
+
+func (d Decimal) rescale(scale int) *big.Int {
+	return new(big.Int).Mul(d.value, pow10(scale-d.scale))
+}
+
+func (d Decimal) round(precision int, rounding string) Decimal {
+	drop := countDigits(d.value) - precision
+	if drop <= 0 {
+		return d
+	}
+
+	return NewDecimal(roundQuotient(d.value, pow10(drop), rounding), d.scale-drop)
+}
+
+func (d Decimal) trim(scale int) Decimal {
+	ten := big.NewInt(10)
+	value := new(big.Int).Set(d.value)
+
+	for d.scale > scale {
+		q, r := new(big.Int).QuoRem(value, ten, new(big.Int))
+		if r.Sign() != 0 {
+			break
+		}
+		value = q
+		d.scale--
+	}
+
+	return Decimal{value: value, scale: d.scale}
+}
+
+func toDecimal(x Expression) Decimal {
+	switch n := x.(type) {
+	case Integer:
+		return Decimal{value: big.NewInt(int64(n))}
+	case BigInt:
+		return Decimal{value: n.value}
+	}
+	return x.(Decimal)
+}
+
+func (c Context) decimalSettings() (int, string) {
+	if c.interpreter == nil {
+		return defaultDecimalPrecision, defaultDecimalRounding
+	}
+	return c.interpreter.DecimalPrecision, c.interpreter.DecimalRounding
+}
+
+func calculateDecimal(ctx Context, op string, a, b Decimal) (Expression, error) {
+	precision, rounding := ctx.decimalSettings()
+
+	scale := a.scale
+	if b.scale > scale {
+		scale = b.scale
+	}
+	x, y := a.rescale(scale), b.rescale(scale)
+
+	switch op {
+	case "+":
+		return Decimal{value: x.Add(x, y), scale: scale}.round(precision, rounding), nil
+	case "-":
+		return Decimal{value: x.Sub(x, y), scale: scale}.round(precision, rounding), nil
+	case "*":
+		return Decimal{value: new(big.Int).Mul(a.value, b.value), scale: a.scale + b.scale}.round(precision, rounding), nil
+	case "/":
+		if b.value.Sign() == 0 {
+			return nil, ArithmeticError{reason: "decimal division by zero"}
+		}
+		return divideDecimal(a, b, precision, rounding), nil
+	case "div":
+		if y.Sign() == 0 {
+			return nil, ArithmeticError{reason: "decimal division by zero"}
+		}
+		return Decimal{value: x.Quo(x, y)}, nil
+	case "%":
+		if y.Sign() == 0 {
+			return nil, ArithmeticError{reason: "decimal modulo by zero"}
+		}
+		return Decimal{value: x.Rem(x, y), scale: scale}, nil
+	case "<":
+		return Boolean(x.Cmp(y) < 0), nil
+	case "<=":
+		return Boolean(x.Cmp(y) <= 0), nil
+	case ">":
+		return Boolean(x.Cmp(y) > 0), nil
+	case ">=":
+		return Boolean(x.Cmp(y) >= 0), nil
+	}
+
+	return nil, ArithmeticError{reason: "unsupported operator " + op}
+}
+
+func divideDecimal(a, b Decimal, precision int, rounding string) Decimal {
+	shift := precision + countDigits(b.value) - countDigits(a.value) + 1
+	if shift < 0 {
+		shift = 0
+	}
+
+	q, r := new(big.Int).QuoRem(new(big.Int).Mul(a.value, pow10(shift)), b.value, new(big.Int))
+	scale := a.scale - b.scale + shift
+
+	if r.Sign() != 0 {
+		q.Mul(q, big.NewInt(10))
+		q.Add(q, big.NewInt(int64(a.value.Sign()*b.value.Sign())))
+		scale++
+	}
+
+	ideal := a.scale - b.scale
+	if ideal < 0 {
+		ideal = 0
+	}
+
+	return NewDecimal(q, scale).round(precision, rounding).trim(ideal)
+}
+
+func powerDecimal(ctx Context, a Decimal, n *big.Int) (Expression, error) {
+	precision, rounding := ctx.decimalSettings()
+
+	if !n.IsInt64() || n.Int64() > decimalExponentLimit || n.Int64() < -decimalExponentLimit {
+		return nil, ArithmeticError{reason: "exponent too large"}
+	}
+
+	e := n.Int64()
+	if e < 0 {
+		e = -e
+	}
+
+	result := Decimal{value: new(big.Int).Exp(a.value, big.NewInt(e), nil), scale: a.scale * int(e)}
+	if n.Sign() >= 0 {
+		return result.round(precision, rounding), nil
+	}
+
+	if result.value.Sign() == 0 {
+		return nil, ArithmeticError{reason: "decimal division by zero"}
+	}
+	return divideDecimal(Decimal{value: big.NewInt(1)}, result, precision, rounding), nil
+}
+
+func roundQuotient(x, d *big.Int, rounding string) *big.Int {
+	q, r := new(big.Int).QuoRem(x, d, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+
+	sign := x.Sign()
+	half := new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).Cmp(d)
+
+	away := false
+	switch rounding {
+	case "up":
+		away = true
+	case "down":
+		away = false
+	case "ceiling":
+		away = sign > 0
+	case "floor":
+		away = sign < 0
+	case "half_up":
+		away = half >= 0
+	case "half_down":
+		away = half > 0
+	default:
+		away = half > 0 || half == 0 && q.Bit(0) == 1
+	}
+
+	if away {
+		q.Add(q, big.NewInt(int64(sign)))
+	}
+	return q
+}
+
+func countDigits(x *big.Int) int {
+	if x.Sign() == 0 {
+		return 1
+	}
+	return len(new(big.Int).Abs(x).String())
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,64 @@
+package tako
+
+import (
+	"testing"
+)
+
+func TestDecimalRounding(t *testing.T) {
+	inputs := []string{"1.25d", "1.35d", "1.21d", "1.27d", "-1.25d", "-1.21d", "-1.27d", "125d", "-135d"}
+
+	tests := []struct {
+		rounding string
+		outputs  []string
+	}{
+		{"half_even", []string{"1.2", "1.4", "1.2", "1.3", "-1.2", "-1.2", "-1.3", "120", "-140"}},
+		{"half_up", []string{"1.3", "1.4", "1.2", "1.3", "-1.3", "-1.2", "-1.3", "130", "-140"}},
+		{"half_down", []string{"1.2", "1.3", "1.2", "1.3", "-1.2", "-1.2", "-1.3", "120", "-130"}},
+		{"up", []string{"1.3", "1.4", "1.3", "1.3", "-1.3", "-1.3", "-1.3", "130", "-140"}},
+		{"down", []string{"1.2", "1.3", "1.2", "1.2", "-1.2", "-1.2", "-1.2", "120", "-130"}},
+		{"ceiling", []string{"1.3", "1.4", "1.3", "1.3", "-1.2", "-1.2", "-1.2", "130", "-130"}},
+		{"floor", []string{"1.2", "1.3", "1.2", "1.2", "-1.3", "-1.3", "-1.3", "120", "-140"}},
+	}
+
+	for _, tt := range tests {
+		for i, input := range inputs {
+			in := NewInterpreter()
+			in.DecimalPrecision = 2
+			in.DecimalRounding = tt.rounding
+
+			result, err := in.Eval(input + " + 0")
+			if err != nil {
+				t.Fatalf("%s with %s: %s", input, tt.rounding, err)
+			}
+			if output := display(result); output != tt.outputs[i] {
+				t.Errorf("%s with %s: expected %s but got %s", input, tt.rounding, tt.outputs[i], output)
+			}
+		}
+	}
+}
+
+func TestDecimalPrecision(t *testing.T) {
+	testEval(t, []evalTest{
+		{src: "decimal_precision(5); 123456789.123d + 0", output: "123460000"},
+		{src: "decimal_precision(5); 1.23456789d * 1", output: "1.2346"},
+		{src: "decimal_precision(5); 99999.9d + 0", output: "100000"},
+		{src: "decimal_precision(3); 1d / 3", output: "0.333"},
+		{src: "decimal_precision(3); 1000d / 3", output: "333"},
+		{src: "decimal_precision(28); 1d / 3", output: "0.3333333333333333333333333333"},
+		{src: "decimal_precision(4); 19.99d * 3 / 3", output: "19.99"},
+	})
+}
+
+func TestDecimalMixing(t *testing.T) {
+	testEval(t, []evalTest{
+		{src: `1d + 1 == 2d`, output: "true"},
+		{src: `1e1048576d == 1e1048576d`, output: "true"},
+	})
+
+	testEvalError(t, []evalTest{
+		{src: `1.5d + 1.5`, output: "TypeError: right operand of + must be integer or decimal but got float"},
+		{src: `1.5 == 1.5d`, output: "TypeError: left operand of == must be integer or decimal but got float"},
+		{src: `1.5d != 1.5`, output: "TypeError: right operand of != must be integer or decimal but got float"},
+		{src: `1e2000000000d`, output: `SyntaxError: syntax error near "1e2000000000d"`},
+	})
+}
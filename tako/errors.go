@@ -0,0 +1,377 @@
+package tako
+
+import (
+	"fmt"
+	"strings"
+)
+
+type RuntimeError interface {
+	error
+
+	Message() string
+}
+
+type LocatedError interface {
+	RuntimeError
+
+	Position() Position
+}
+
+type NotDefinedError Identifier
+
+func (e NotDefinedError) Error() string {
+	return fmt.Sprintf("%s: %s%s", e.Position(), e.Message(), identifierSnippet(Identifier(e)))
+}
+
+func (e NotDefinedError) Message() string {
+	return fmt.Sprintf("%s is not defined", Identifier(e))
+}
+
+func (e NotDefinedError) Position() Position {
+	return Identifier(e).Position()
+}
+
+type AlreadyDefinedError Identifier
+
+func (e AlreadyDefinedError) Error() string {
+	return fmt.Sprintf("%s: %s%s", e.Position(), e.Message(), identifierSnippet(Identifier(e)))
+}
+
+func (e AlreadyDefinedError) Message() string {
+	return fmt.Sprintf("%s is already defined", Identifier(e))
+}
+
+func (e AlreadyDefinedError) Position() Position {
+	return Identifier(e).Position()
+}
+
+type OutOfBoundsError struct {
+	min int
+	max int
+	got Expression
+	pos Position
+	end Position
+}
+
+func (e OutOfBoundsError) Error() string {
+	if e.pos == (Position{}) {
+		return e.Message()
+	}
+	return fmt.Sprintf("%s: %s%s", e.pos, e.Message(), snippet(e.pos, e.end))
+}
+
+func (e OutOfBoundsError) Message() string {
+	return fmt.Sprintf("index %s is out of bounds (must be between %d and %d)", e.got, e.min, e.max)
+}
+
+func (e OutOfBoundsError) Position() Position {
+	return e.pos
+}
+
+type KeyError struct {
+	key string
+	pos Position
+	end Position
+}
+
+func (e KeyError) Error() string {
+	if e.pos == (Position{}) {
+		return e.Message()
+	}
+	return fmt.Sprintf("%s: %s%s", e.pos, e.Message(), snippet(e.pos, e.end))
+}
+
+func (e KeyError) Message() string {
+	return fmt.Sprintf("key %s is not found in object", String(e.key))
+}
+
+func (e KeyError) Position() Position {
+	return e.pos
+}
+
+type NotFunctionError struct {
+	value Expression
+	pos   Position
+	end   Position
+}
+
+func (e NotFunctionError) Error() string {
+	return fmt.Sprintf("%s: %s%s", e.pos, e.Message(), snippet(e.pos, e.end))
+}
+
+func (e NotFunctionError) Message() string {
+	return fmt.Sprintf("%s is not function", e.value)
+}
+
+func (e NotFunctionError) Position() Position {
+	return e.pos
+}
+
+type SyntaxError struct {
+	pos     Position
+	literal string
+	line    string
+}
+
+func (e SyntaxError) Error() string {
+	return fmt.Sprintf("%s: %s:\n%s\n%s", e.pos, e.Message(), e.line, underline(e.line, e.pos.Column, len(e.literal)))
+}
+
+func (e SyntaxError) Message() string {
+	return fmt.Sprintf("syntax error near %#v", e.literal)
+}
+
+func (e SyntaxError) Position() Position {
+	return e.pos
+}
+
+func (e SyntaxError) Literal() string {
+	return e.literal
+}
+
+func (e SyntaxError) SourceLine() string {
+	return e.line
+}
+
+type MissmatchArgumentError struct {
+	excepted int
+	got      int
+	pos      Position
+	end      Position
+	name     string
+}
+
+func (e MissmatchArgumentError) Error() string {
+	return fmt.Sprintf("%s: %s%s", e.pos, e.Message(), snippet(e.pos, e.end))
+}
+
+func (e MissmatchArgumentError) Message() string {
+	fname := e.name
+	if fname == "" {
+		fname = "[unnamed]"
+	}
+	return fmt.Sprintf("%s excepted %d arguments but got %d arguments", fname, e.excepted, e.got)
+}
+
+func (e MissmatchArgumentError) Position() Position {
+	return e.pos
+}
+
+type ImportError struct {
+	path   string
+	reason string
+	pos    Position
+	end    Position
+}
+
+func (e ImportError) Error() string {
+	return fmt.Sprintf("%s: %s%s", e.pos, e.Message(), snippet(e.pos, e.end))
+}
+
+func (e ImportError) Message() string {
+	return fmt.Sprintf("can not import %#v: %s", e.path, e.reason)
+}
+
+func (e ImportError) Position() Position {
+	return e.pos
+}
+
+type ConditionTypeError struct {
+	pos Position
+	end Position
+}
+
+func (e ConditionTypeError) Error() string {
+	return fmt.Sprintf("%s: %s%s", e.pos, e.Message(), snippet(e.pos, e.end))
+}
+
+func (e ConditionTypeError) Message() string {
+	return "condition value must be boolean value"
+}
+
+func (e ConditionTypeError) Position() Position {
+	return e.pos
+}
+
+type TypeError struct {
+	name    string
+	excepts []string
+	got     string
+	pos     Position
+	end     Position
+}
+
+func (e TypeError) Error() string {
+	return fmt.Sprintf("%s: %s%s", e.pos, e.Message(), snippet(e.pos, e.end))
+}
+
+func (e TypeError) Message() string {
+	except := ""
+	if len(e.excepts) == 1 {
+		except = e.excepts[0]
+	} else {
+		except = strings.Join(e.excepts[:len(e.excepts)-1], ", ") + " or " + e.excepts[len(e.excepts)-1]
+	}
+	if e.got != "" {
+		return fmt.Sprintf("%s must be %s but got %s", e.name, except, e.got)
+	}
+	return fmt.Sprintf("%s must be %s", e.name, except)
+}
+
+func (e TypeError) Position() Position {
+	return e.pos
+}
+
+type ArithmeticError struct {
+	reason string
+	pos    Position
+	end    Position
+}
+
+func (e ArithmeticError) Error() string {
+	if e.pos == (Position{}) {
+		return e.Message()
+	}
+	return fmt.Sprintf("%s: %s%s", e.pos, e.Message(), snippet(e.pos, e.end))
+}
+
+func (e ArithmeticError) Message() string {
+	return e.reason
+}
+
+func (e ArithmeticError) Position() Position {
+	return e.pos
+}
+
+type ConversionError struct {
+	value Expression
+	to    string
+	pos   Position
+	end   Position
+}
+
+func (e ConversionError) Error() string {
+	if e.pos == (Position{}) {
+		return e.Message()
+	}
+	return fmt.Sprintf("%s: %s%s", e.pos, e.Message(), snippet(e.pos, e.end))
+}
+
+func (e ConversionError) Message() string {
+	return fmt.Sprintf("can not convert %s to %s", e.value, e.to)
+}
+
+func (e ConversionError) Position() Position {
+	return e.pos
+}
+
+type InternalError struct {
+	value interface{}
+	stack []byte
+}
+
+func (e InternalError) Error() string {
+	return e.Message()
+}
+
+func (e InternalError) Message() string {
+	return fmt.Sprintf("internal error: %v", e.value)
+}
+
+func (e InternalError) Stack() []byte {
+	return e.stack
+}
+
+type ThrownError struct {
+	value Expression
+	pos   Position
+	end   Position
+}
+
+func (e ThrownError) Error() string {
+	return fmt.Sprintf("%s: %s%s", e.pos, e.Message(), snippet(e.pos, e.end))
+}
+
+func (e ThrownError) Message() string {
+	if s, ok := e.value.(String); ok {
+		return string(s)
+	}
+	return fmt.Sprint(e.value)
+}
+
+func (e ThrownError) Position() Position {
+	return e.pos
+}
+
+func (e ThrownError) Value() Expression {
+	return e.value
+}
+
+func ErrorToExpression(err error) Expression {
+	if te, ok := err.(TracedError); ok {
+		err = te.Err
+	}
+
+	if te, ok := err.(ThrownError); ok {
+		return te.value
+	}
+
+	obj := NewObject()
+
+	kind := strings.TrimPrefix(strings.TrimPrefix(fmt.Sprintf("%T", err), "*"), "tako.")
+	obj.Named["kind"] = String(kind)
+
+	if re, ok := err.(RuntimeError); ok {
+		obj.Named["message"] = String(re.Message())
+	} else {
+		obj.Named["message"] = String(err.Error())
+	}
+
+	if le, ok := err.(LocatedError); ok {
+		obj.Named["position"] = String(le.Position().String())
+	} else {
+		obj.Named["position"] = Null{}
+	}
+
+	return obj
+}
+
+func snippet(pos, end Position) string {
+	line := pos.SourceLine()
+	if line == "" {
+		return ""
+	}
+
+	length := 1
+	if end.Filename != "" {
+		if end.Line == pos.Line {
+			length = end.Column - pos.Column
+		} else {
+			length = len([]rune(line)) - pos.Column
+		}
+	}
+
+	return ":\n" + line + "\n" + underline(line, pos.Column, length)
+}
+
+func identifierSnippet(ident Identifier) string {
+	return snippet(ident.Pos, ident.Pos.advance(ident.Key))
+}
+
+func underline(line string, column, length int) string {
+	indent := []rune(line)
+	if column < len(indent) {
+		indent = indent[:column]
+	}
+	for i, r := range indent {
+		if r != '\t' {
+			indent[i] = ' '
+		}
+	}
+
+	if length < 1 {
+		length = 1
+	}
+
+	return string(indent) + strings.Repeat("^", length)
+}
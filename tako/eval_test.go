@@ -0,0 +1,75 @@
+package tako
+
+import (
+	"testing"
+)
+
+type engine struct {
+	name  string
+	useVM bool
+}
+
+var engines = []engine{
+	{"tree-walker", false},
+	{"vm", true},
+}
+
+func (e engine) interpreter() *Interpreter {
+	in := NewInterpreter()
+	in.UseVM = e.useVM
+	return in
+}
+
+type evalTest struct {
+	name   string
+	src    string
+	output string
+}
+
+func (tt evalTest) String() string {
+	if tt.name != "" {
+		return tt.name
+	}
+	return tt.src
+}
+
+// testEval evaluates every test on every engine and compares the displayed result.
+func testEval(t *testing.T, tests []evalTest) {
+	t.Helper()
+
+	for _, tt := range tests {
+		for _, e := range engines {
+			result, err := e.interpreter().Eval(tt.src)
+			if err != nil {
+				t.Errorf("%s (%s): failed to evaluate: %s", tt, e.name, err)
+				continue
+			}
+			if output := display(result); output != tt.output {
+				t.Errorf("%s (%s): expected %s but got %s", tt, e.name, tt.output, output)
+			}
+		}
+	}
+}
+
+// testEvalError is testEval for code that must fail. The output of each test is "Kind: message".
+func testEvalError(t *testing.T, tests []evalTest) {
+	t.Helper()
+
+	for _, tt := range tests {
+		for _, e := range engines {
+			_, err := e.interpreter().Eval(tt.src)
+			if err == nil {
+				t.Errorf("%s (%s): expected %s but got no error", tt, e.name, tt.output)
+				continue
+			}
+			if output := errorString(err); output != tt.output {
+				t.Errorf("%s (%s): expected %s but got %s", tt, e.name, tt.output, output)
+			}
+		}
+	}
+}
+
+func errorString(err error) string {
+	obj := ErrorToExpression(err).(*Object)
+	return string(obj.Named["kind"].(String)) + ": " + string(obj.Named["message"].(String))
+}
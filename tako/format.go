@@ -0,0 +1,714 @@
+package tako
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	formatWidth    = 80
+	formatTabWidth = 4
+
+	precedenceDefine  = 1
+	precedenceOr      = 2
+	precedenceAnd     = 3
+	precedenceNot     = 4
+	precedenceCompare = 5
+	precedenceBitOr   = 6
+	precedenceBitXor  = 7
+	precedenceBitAnd  = 8
+	precedenceShift   = 9
+	precedenceAdd     = 10
+	precedenceMul     = 11
+	precedencePow     = 12
+	precedenceUnary   = 13
+	precedenceMember  = 14
+)
+
+var (
+	formatBinaryOperators = map[string]struct {
+		symbol     string
+		precedence int
+		right      bool
+	}{
+		"::=:":  {":=", precedenceDefine, true},
+		":=:":   {"=", precedenceDefine, true},
+		":==:":  {"==", precedenceCompare, false},
+		":!=:":  {"!=", precedenceCompare, false},
+		":<:":   {"<", precedenceCompare, false},
+		":<=:":  {"<=", precedenceCompare, false},
+		":>:":   {">", precedenceCompare, false},
+		":>=:":  {">=", precedenceCompare, false},
+		":|:":   {"|", precedenceBitOr, false},
+		":xor:": {"xor", precedenceBitXor, false},
+		":&:":   {"&", precedenceBitAnd, false},
+		":<<:":  {"<<", precedenceShift, false},
+		":>>:":  {">>", precedenceShift, false},
+		":+:":   {"+", precedenceAdd, false},
+		":-:":   {"-", precedenceAdd, false},
+		":*:":   {"*", precedenceMul, false},
+		":/:":   {"/", precedenceMul, false},
+		":div:": {"div", precedenceMul, false},
+		":%:":   {"%", precedenceMul, false},
+		":^:":   {"^", precedencePow, false},
+	}
+
+	formatUnaryOperators = map[string]string{
+		"-:": "-",
+		"!:": "!",
+	}
+)
+
+type formatter struct {
+	lines    []string
+	comments []Comment
+	limit    int
+	end      Position
+}
+
+func Format(expr Expression, comments []Comment, source string) string {
+	f := &formatter{
+		lines:    strings.Split(source, "\n"),
+		comments: comments,
+		limit:    math.MaxInt32,
+	}
+
+	return f.statements(expr, 0) + "\n"
+}
+
+func operatorOf(expr Expression) (string, bool) {
+	fc, ok := expr.(FunctionCall)
+	if !ok {
+		return "", false
+	}
+
+	ident, ok := fc.Function.(Identifier)
+	if !ok || ident.Pos.Filename != "builtin" {
+		return "", false
+	}
+
+	return ident.Key, true
+}
+
+func precedenceOf(expr Expression) int {
+	switch e := expr.(type) {
+	case Condition, Try, While, For:
+		return 0
+
+	case Logical:
+		if e.Left == nil {
+			return precedenceNot
+		} else if e.Operator == "&&" {
+			return precedenceAnd
+		}
+		return precedenceOr
+	}
+
+	op, ok := operatorOf(expr)
+	if !ok {
+		return precedenceMember
+	}
+
+	if b, ok := formatBinaryOperators[op]; ok && len(expr.(FunctionCall).Arguments) == 2 {
+		return b.precedence
+	}
+	if _, ok := formatUnaryOperators[op]; ok && len(expr.(FunctionCall).Arguments) == 1 {
+		return precedenceUnary
+	}
+	if op == ":.=:" || op == ":[]=:" || op == ":.:=:" || op == ":[]:=:" {
+		return precedenceDefine
+	}
+
+	return precedenceMember
+}
+
+func firstLine(expr Expression) (int, bool) {
+	start, _, ok := spanOf(expr)
+	return start.Line, ok
+}
+
+func (f *formatter) isBlank(line int) bool {
+	return line >= 0 && line < len(f.lines) && strings.TrimSpace(f.lines[line]) == ""
+}
+
+func (f *formatter) indentation(line int) int {
+	if line < 0 || line >= len(f.lines) {
+		return 0
+	}
+	return len(f.lines[line]) - len(strings.TrimLeft(f.lines[line], " \t"))
+}
+
+func (f *formatter) takeComment(limit int, accept func(Comment) bool) (Comment, bool) {
+	for i, c := range f.comments {
+		if c.Pos.Line >= limit || f.end != (Position{}) && !positionBefore(c.Pos, f.end) {
+			break
+		}
+		if accept(c) {
+			f.comments = append(f.comments[:i:i], f.comments[i+1:]...)
+			return c, true
+		}
+	}
+	return Comment{}, false
+}
+
+func (f *formatter) leading(pos Position) string {
+	s := ""
+	before := func(c Comment) bool {
+		return c.Pos.Line == pos.Line && positionBefore(c.Pos, pos)
+	}
+	for c, ok := f.takeComment(pos.Line+1, before); ok; c, ok = f.takeComment(pos.Line+1, before) {
+		s += c.Text + " "
+	}
+	return s
+}
+
+func (f *formatter) between(start, end Position) string {
+	s := ""
+	inside := func(c Comment) bool {
+		return !positionBefore(c.Pos, start) && positionBefore(c.Pos, end) && !strings.HasPrefix(c.Text, "//")
+	}
+	for c, ok := f.takeComment(end.Line+1, inside); ok; c, ok = f.takeComment(end.Line+1, inside) {
+		s += " " + c.Text
+	}
+	return s
+}
+
+func (f *formatter) within(end Position, format func() string) string {
+	outer := f.end
+	if outer == (Position{}) || positionBefore(end, outer) {
+		f.end = end
+	}
+	defer func() { f.end = outer }()
+
+	return format()
+}
+
+func (f *formatter) plain(expr Expression, depth int) string {
+	comments := f.comments
+	f.comments = nil
+	defer func() { f.comments = comments }()
+
+	return f.expression(expr, depth)
+}
+
+func ownLine(c Comment) bool {
+	return !c.Trailing
+}
+
+func trailingAfter(line int) func(Comment) bool {
+	return func(c Comment) bool {
+		return c.Trailing && c.Pos.Line >= line
+	}
+}
+
+func insideOf(column int) func(Comment) bool {
+	return func(c Comment) bool {
+		return !c.Trailing && c.Pos.Column >= column
+	}
+}
+
+func indent(depth int) string {
+	return strings.Repeat("\t", depth)
+}
+
+func fits(s string, depth int) bool {
+	return !strings.Contains(s, "\n") && depth*formatTabWidth+len(s) <= formatWidth
+}
+
+func statementsOf(expr Expression) []Expression {
+	if el, ok := expr.(ExpressionList); ok {
+		return el
+	}
+	return []Expression{expr}
+}
+
+func (f *formatter) statements(expr Expression, depth int) string {
+	var lines []string
+	emit := func(line int, text string) {
+		if len(lines) > 0 && f.isBlank(line-1) {
+			lines = append(lines, "")
+		}
+		lines = append(lines, text)
+	}
+
+	outer := f.limit
+	defer func() { f.limit = outer }()
+
+	stmts := statementsOf(expr)
+	column := -1
+
+	for i, e := range stmts {
+		line, ok := firstLine(e)
+		if !ok {
+			line = -1
+		} else {
+			if column < 0 {
+				column = f.indentation(line)
+			}
+			for c, ok := f.takeComment(line, ownLine); ok; c, ok = f.takeComment(line, ownLine) {
+				emit(c.Pos.Line, indent(depth)+c.Text)
+			}
+		}
+
+		f.limit = outer
+		for _, next := range stmts[i+1:] {
+			if l, ok := firstLine(next); ok {
+				f.limit = l
+				break
+			}
+		}
+
+		s := f.expression(e, depth)
+		for c, ok := f.takeComment(f.limit, trailingAfter(line)); ok; c, ok = f.takeComment(f.limit, trailingAfter(line)) {
+			if c.Pos.Line == line {
+				if i := strings.Index(s, "\n"); i >= 0 {
+					s = s[:i] + " " + c.Text + s[i:]
+					continue
+				}
+			}
+			s += " " + c.Text
+		}
+
+		emit(line, indent(depth)+s)
+	}
+
+	inside := insideOf(column)
+	if depth == 0 {
+		inside = func(Comment) bool { return true }
+	}
+	for c, ok := f.takeComment(outer, inside); ok; c, ok = f.takeComment(outer, inside) {
+		emit(c.Pos.Line, indent(depth)+c.Text)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (f *formatter) block(expr Expression, depth int, inline bool, end Position) string {
+	return f.within(end, func() string {
+		return f.body(expr, depth, inline)
+	})
+}
+
+func (f *formatter) body(expr Expression, depth int, inline bool) string {
+	stmts := statementsOf(expr)
+
+	if len(stmts) == 0 {
+		return "{}"
+	}
+
+	if inline && len(stmts) == 1 {
+		comments := f.comments
+		s := "{ " + f.expression(stmts[0], depth+1) + " }"
+		if fits(s, depth) {
+			return s
+		}
+		f.comments = comments
+	}
+
+	return "{\n" + f.statements(expr, depth+1) + "\n" + indent(depth) + "}"
+}
+
+func (f *formatter) operand(expr Expression, depth int, parenthesize bool) string {
+	s := f.expression(expr, depth)
+	if parenthesize {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+func (f *formatter) expression(expr Expression, depth int) string {
+	if start, _, ok := spanOf(expr); ok {
+		if s := f.leading(start); s != "" {
+			return s + f.expression(expr, depth)
+		}
+	}
+
+	switch e := expr.(type) {
+	case Integer:
+		return e.String()
+
+	case BigInt:
+		return e.String()
+
+	case Float:
+		return e.String()
+
+	case Decimal:
+		return e.String() + "d"
+
+	case String:
+		return quote(string(e))
+
+	case Literal:
+		if e.raw != "" {
+			return e.raw
+		}
+		return f.expression(e.Value, depth)
+
+	case Identifier:
+		return e.Key
+
+	case ExpressionList:
+		ss := make([]string, len(e))
+		for i, x := range e {
+			ss[i] = f.expression(x, depth)
+		}
+		return "(" + strings.Join(ss, "; ") + ")"
+
+	case FunctionDefine:
+		return f.function(e, depth)
+
+	case Condition:
+		return f.condition(e, depth)
+
+	case Try:
+		body := f.block(e.Body, depth, false, e.bodyEnd)
+		return "try " + body + f.between(e.bodyEnd, e.catchPos) + " catch (" + e.Variable.Key + ") " + f.block(e.Catch, depth, false, e.End)
+
+	case Logical:
+		return f.logical(e, depth)
+
+	case Interpolation:
+		return f.interpolation(e, depth)
+
+	case While:
+		return "while " + f.expression(e.Condition, depth) + " " + f.block(e.Body, depth, false, e.End)
+
+	case For:
+		return "for " + e.Variable.Key + " in " + f.expression(e.Iterable, depth) + " " + f.block(e.Body, depth, false, e.End)
+
+	case Break:
+		if e.Value == nil {
+			return "break"
+		}
+		return "break(" + f.expression(e.Value, depth) + ")"
+
+	case Continue:
+		return "continue"
+
+	case Return:
+		if e.Value == nil {
+			return "return"
+		}
+		return "return(" + f.expression(e.Value, depth) + ")"
+
+	case *Object:
+		return f.object(e, depth)
+
+	case FunctionCall:
+		return f.call(e, depth)
+	}
+
+	return ""
+}
+
+func (f *formatter) function(fd FunctionDefine, depth int) string {
+	if fd.block {
+		return f.block(fd.Expression, depth, f.inline(fd), fd.End)
+	}
+
+	args := make([]string, len(fd.Arguments))
+	for i, a := range fd.Arguments {
+		args[i] = f.leading(a.Pos) + a.Key
+	}
+	if fd.VariableArgument != nil {
+		args = append(args, f.leading(fd.VariableArgument.Pos)+fd.VariableArgument.Key+"...")
+	}
+
+	return "(" + strings.Join(args, ", ") + ")" + f.block(fd.Expression, depth, f.inline(fd), fd.End)
+}
+
+func (f *formatter) inline(fd FunctionDefine) bool {
+	stmts := statementsOf(fd.Expression)
+	if len(stmts) == 0 {
+		return true
+	}
+
+	line, ok := firstLine(stmts[0])
+	return !ok || line == fd.open.Line
+}
+
+func (f *formatter) condition(c Condition, depth int) string {
+	if c.Else == nil {
+		return "if " + f.expression(c.Condition, depth) + " " + f.block(c.Then, depth, false, c.End)
+	}
+
+	s := "if " + f.expression(c.Condition, depth) + " " + f.block(c.Then, depth, false, c.thenEnd)
+	s += f.between(c.thenEnd, c.elsePos)
+
+	if elif, ok := c.Else.(Condition); ok {
+		return s + " else " + f.condition(elif, depth)
+	}
+	return s + " else " + f.block(c.Else, depth, false, c.End)
+}
+
+func (f *formatter) logical(l Logical, depth int) string {
+	p := precedenceOf(l)
+
+	right := f.operand(l.Right, depth, precedenceOf(l.Right) < p || (l.Left != nil && precedenceOf(l.Right) == p))
+	if l.Left == nil {
+		return "not " + right
+	}
+
+	return f.operand(l.Left, depth, precedenceOf(l.Left) < p) + " " + l.Operator + " " + right
+}
+
+type element struct {
+	text string
+	line int
+}
+
+func (f *formatter) elements(obj *Object, depth int) []element {
+	var elems []element
+
+	if len(obj.entries) == len(obj.Indexed)+len(obj.Named) {
+		index := 0
+		for _, e := range obj.entries {
+			var value Expression
+			var text string
+			if e.key == "" {
+				value = obj.Indexed[index]
+				text = f.expression(value, depth+1)
+				index++
+			} else {
+				value = obj.Named[e.key]
+				text = f.leading(e.pos) + e.key + ": " + f.expression(value, depth+1)
+			}
+
+			line, ok := firstLine(value)
+			if !ok || e.key != "" {
+				line = e.pos.Line
+			}
+
+			elems = append(elems, element{text: text, line: line})
+		}
+
+		return elems
+	}
+
+	for _, x := range obj.Indexed {
+		elems = append(elems, element{text: f.expression(x, depth+1), line: -1})
+	}
+
+	keys := make([]string, 0, len(obj.Named))
+	for k := range obj.Named {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		elems = append(elems, element{text: k + ": " + f.expression(obj.Named[k], depth+1), line: -1})
+	}
+
+	return elems
+}
+
+func (f *formatter) object(obj *Object, depth int) string {
+	elems := f.elements(obj, depth)
+
+	texts := make([]string, len(elems))
+	multiline := obj.multiline
+	for i, e := range elems {
+		texts[i] = e.text
+		multiline = multiline || e.line != elems[0].line
+	}
+
+	flat := "[" + strings.Join(texts, ", ") + "]"
+	if !multiline && fits(flat, depth) {
+		return flat
+	}
+
+	lines := []string{"["}
+	emit := func(line int, text string) {
+		if len(lines) > 1 && f.isBlank(line-1) {
+			lines = append(lines, "")
+		}
+		lines = append(lines, text)
+	}
+
+	column := -1
+	for i, e := range elems {
+		if e.line >= 0 {
+			if column < 0 {
+				column = f.indentation(e.line)
+			}
+			for c, ok := f.takeComment(e.line, ownLine); ok; c, ok = f.takeComment(e.line, ownLine) {
+				emit(c.Pos.Line, indent(depth+1)+c.Text)
+			}
+		}
+
+		s := indent(depth+1) + e.text + ","
+
+		limit := f.limit
+		if i+1 < len(elems) && elems[i+1].line >= 0 {
+			limit = elems[i+1].line
+		}
+		for c, ok := f.takeComment(limit, trailingAfter(e.line)); ok; c, ok = f.takeComment(limit, trailingAfter(e.line)) {
+			s += " " + c.Text
+		}
+
+		emit(e.line, s)
+	}
+
+	inside := insideOf(column)
+	for c, ok := f.takeComment(f.limit, inside); ok; c, ok = f.takeComment(f.limit, inside) {
+		emit(c.Pos.Line, indent(depth+1)+c.Text)
+	}
+
+	return strings.Join(append(lines, indent(depth)+"]"), "\n")
+}
+
+func (f *formatter) arguments(args []Expression, depth int, end Position) string {
+	ss := make([]string, len(args))
+	for i, a := range args {
+		ss[i] = f.expression(a, depth)
+
+		if _, last, ok := spanOf(a); ok {
+			next := end
+			if i+1 < len(args) {
+				if start, _, ok := spanOf(args[i+1]); ok {
+					next = start
+				}
+			}
+			ss[i] += f.between(last, next)
+		}
+	}
+	return "(" + strings.Join(ss, ", ") + ")"
+}
+
+func (f *formatter) receiver(expr Expression, depth int) string {
+	return f.operand(expr, depth, precedenceOf(expr) < precedenceMember)
+}
+
+func (f *formatter) member(fc FunctionCall, depth int) (string, bool) {
+	op, ok := operatorOf(fc)
+	if !ok || len(fc.Arguments) < 2 {
+		return "", false
+	}
+
+	switch op {
+	case ":.:":
+		if name, ok := fc.Arguments[1].(Identifier); ok && len(fc.Arguments) == 2 {
+			return f.receiver(fc.Arguments[0], depth) + "." + name.Key, true
+		}
+
+	case ":[]:":
+		if len(fc.Arguments) == 2 {
+			return f.receiver(fc.Arguments[0], depth) + "[" + f.expression(fc.Arguments[1], depth) + "]", true
+		}
+
+	case ":.=:", ":.:=:":
+		if name, ok := fc.Arguments[1].(Identifier); ok && len(fc.Arguments) == 3 {
+			return f.receiver(fc.Arguments[0], depth) + "." + name.Key + f.assignment(fc, depth), true
+		}
+
+	case ":[]=:", ":[]:=:":
+		if len(fc.Arguments) == 3 {
+			return f.receiver(fc.Arguments[0], depth) + "[" + f.expression(fc.Arguments[1], depth) + "]" + f.assignment(fc, depth), true
+		}
+	}
+
+	return "", false
+}
+
+func (f *formatter) assignment(fc FunctionCall, depth int) string {
+	value := fc.Arguments[2]
+	symbol := "="
+	if op, _ := operatorOf(fc); op == ":.:=:" || op == ":[]:=:" {
+		symbol = ":="
+	}
+
+	if calc, ok := value.(FunctionCall); ok && fc.compound != "" && len(calc.Arguments) == 2 {
+		value = calc.Arguments[1]
+		symbol = fc.compound + "="
+	}
+
+	return " " + symbol + " " + f.operand(value, depth, precedenceOf(value) < precedenceDefine)
+}
+
+func (f *formatter) call(fc FunctionCall, depth int) string {
+	if s, ok := f.member(fc, depth); ok {
+		return s
+	}
+
+	if op, ok := operatorOf(fc); ok {
+		if b, ok := formatBinaryOperators[op]; ok && len(fc.Arguments) == 2 {
+			if b.precedence == precedenceDefine {
+				if _, ok := fc.Arguments[0].(Identifier); !ok {
+					return fc.Function.(Identifier).Key + f.arguments(fc.Arguments, depth, fc.End)
+				}
+			}
+
+			lp, rp := precedenceOf(fc.Arguments[0]), precedenceOf(fc.Arguments[1])
+			left := f.operand(fc.Arguments[0], depth, lp < b.precedence || (lp == b.precedence && b.right) || (lp == precedenceUnary && b.precedence > precedenceAdd && isNegation(fc.Arguments[0])))
+			right := f.operand(fc.Arguments[1], depth, (rp < b.precedence && b.precedence != precedenceDefine) || (rp == b.precedence && !b.right) || (rp == precedenceUnary && b.precedence > precedenceAdd && isNegation(fc.Arguments[1])))
+
+			return left + " " + b.symbol + " " + right
+		}
+
+		if symbol, ok := formatUnaryOperators[op]; ok && len(fc.Arguments) == 1 {
+			return symbol + f.operand(fc.Arguments[0], depth, precedenceOf(fc.Arguments[0]) < precedenceMember)
+		}
+	}
+
+	if m, ok := fc.Function.(FunctionCall); ok && len(fc.Arguments) > 0 {
+		if f.plain(m.Arguments[0], depth) == f.plain(fc.Arguments[0], depth) {
+			if s, ok := f.member(m, depth); ok {
+				return s + f.arguments(fc.Arguments[1:], depth, fc.End)
+			}
+		}
+		if op, ok := operatorOf(m); ok && (op == ":.:" || op == ":[]:") {
+			return "(" + f.expression(m, depth) + ")" + f.arguments(fc.Arguments, depth, fc.End)
+		}
+	}
+
+	return f.receiver(fc.Function, depth) + f.arguments(fc.Arguments, depth, fc.End)
+}
+
+func isNegation(expr Expression) bool {
+	op, ok := operatorOf(expr)
+	return ok && op == "-:"
+}
+
+func (f *formatter) interpolation(i Interpolation, depth int) string {
+	if i.raw != "" {
+		return i.raw
+	}
+
+	s := "\""
+	for _, x := range i.Parts {
+		if str, ok := x.(String); ok {
+			s += escape(string(str))
+		} else {
+			s += "${" + f.expression(x, depth) + "}"
+		}
+	}
+	return s + "\""
+}
+
+func quote(s string) string {
+	return "\"" + escape(s) + "\""
+}
+
+func escape(s string) string {
+	r := strings.NewReplacer(
+		"\\", "\\\\",
+		"\"", "\\\"",
+		"\n", "\\n",
+		"\r", "\\r",
+		"\t", "\\t",
+		"${", "\\${",
+	)
+
+	var b strings.Builder
+	for _, c := range r.Replace(s) {
+		if unicode.IsPrint(c) || c == ' ' {
+			b.WriteRune(c)
+		} else {
+			fmt.Fprintf(&b, "\\u{%x}", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package tako
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func formatString(t *testing.T, src, filename string) string {
+	t.Helper()
+
+	errs, expr, comments := ParseWithComments(strings.NewReader(src), filename)
+	if len(errs) > 0 {
+		t.Fatalf("%s: %s", filename, errs[0])
+	}
+	return Format(expr, comments, src)
+}
+
+func TestFormatGolden(t *testing.T) {
+	paths, err := filepath.Glob("testdata/format/*.tako")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no test data found")
+	}
+
+	for _, path := range paths {
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		golden, err := ioutil.ReadFile(strings.TrimSuffix(path, ".tako") + ".golden")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if output := formatString(t, string(src), path); output != string(golden) {
+			t.Errorf("%s: unexpected output\n--- expected\n%s--- got\n%s", path, golden, output)
+		}
+
+		if output := formatString(t, string(golden), path); output != string(golden) {
+			t.Errorf("%s: formatting is not stable\n--- expected\n%s--- got\n%s", path, golden, output)
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package tako
 
 import (
 	"fmt"
@@ -18,6 +18,13 @@ type FunctionDefine struct {
 	VariableArgument *Identifier
 	Expression       Expression
 	Pos              Position
+	End              Position
+	Doc              string
+
+	scope *Context
+	open  Position
+	block bool
+	name  string
 }
 
 func (fd FunctionDefine) String() string {
@@ -33,11 +40,12 @@ func (fd FunctionDefine) String() string {
 }
 
 func (fd FunctionDefine) Compute(ctx Context) (Expression, error) {
+	fd.scope = &ctx
 	return fd, nil
 }
 
 func (fd FunctionDefine) Computable(ctx Context) bool {
-	return false
+	return fd.scope == nil
 }
 
 func (fd FunctionDefine) Position() Position {
@@ -53,7 +61,12 @@ func (fd FunctionDefine) GetVariableArgument() *Identifier {
 }
 
 func (fd FunctionDefine) Call(ctx Context, args map[Identifier]Expression, variables *Object) (Expression, error) {
-	newCtx := ctx.MakeScope()
+	scope := ctx
+	if fd.scope != nil {
+		scope = *fd.scope
+	}
+
+	newCtx := scope.MakeScope()
 	for k, v := range args {
 		v_, err := ctx.ComputeRecursive(v)
 		if err != nil {
@@ -74,13 +87,24 @@ func (fd FunctionDefine) Call(ctx Context, args map[Identifier]Expression, varia
 		newCtx.Define(*vi, vo)
 	}
 
-	return fd.Expression.Compute(newCtx)
+	newCtx.frame = ctx.calleeFrame()
+	newCtx.tail = true
+
+	return TailCall{
+		Expression: fd.Expression,
+		Context:    newCtx,
+		function:   true,
+	}, nil
 }
 
 type FunctionCall struct {
 	Function  Expression
 	Arguments []Expression
 	Pos       Position
+	End       Position
+
+	compound string
+	method   bool
 }
 
 func (fc FunctionCall) String() string {
@@ -99,7 +123,7 @@ func (fc FunctionCall) GetFunction(ctx Context) (Function, error) {
 
 	f, ok := raw.(Function)
 	if !ok {
-		return nil, NotFunctionError{value: fc.Function, pos: fc.Pos}
+		return nil, NotFunctionError{value: fc.Function, pos: fc.Pos, end: fc.End}
 	}
 
 	return f, nil
@@ -113,32 +137,55 @@ func (fc FunctionCall) Compute(ctx Context) (Expression, error) {
 
 	va := f.GetVariableArgument()
 
-	if (va == nil && len(fc.Arguments) != len(f.GetArguments())) || (va != nil && len(fc.Arguments) < len(f.GetArguments())) {
-		err := MissmatchArgumentError{
-			excepted: len(f.GetArguments()),
-			got:      len(fc.Arguments),
-			pos:      fc.Position(),
+	name := ""
+	if ident, ok := fc.Function.(Identifier); ok {
+		name = ident.String()
+	}
+
+	arguments := fc.Arguments
+	if fc.method {
+		receiver, err := ctx.ComputeRecursive(arguments[0])
+		if err != nil {
+			return nil, err
 		}
-		if ident, ok := fc.Function.(Identifier); ok {
-			err.name = ident.String()
+
+		if isModule(receiver) {
+			arguments = arguments[1:]
+		} else {
+			arguments = append([]Expression{receiver}, arguments[1:]...)
+		}
+	}
+
+	if (va == nil && len(arguments) != len(f.GetArguments())) || (va != nil && len(arguments) < len(f.GetArguments())) {
+		return nil, MissmatchArgumentError{
+			excepted: len(f.GetArguments()),
+			got:      len(arguments),
+			pos:      fc.Pos,
+			end:      fc.End,
+			name:     name,
 		}
-		return nil, err
 	}
 
 	args := make(map[Identifier]Expression)
 	for i, x := range f.GetArguments() {
-		args[x] = fc.Arguments[i]
+		args[x] = arguments[i]
 	}
 
 	var obj *Object
 	if va != nil {
 		obj = NewObject()
-		for _, x := range fc.Arguments[len(f.GetArguments()):] {
+		for _, x := range arguments[len(f.GetArguments()):] {
 			obj.Indexed = append(obj.Indexed, x)
 		}
 	}
 
-	return f.Call(ctx, args, obj)
+	result, err := f.Call(ctx.pushFrame(name, fc.Pos, fc.End), args, obj)
+	if te, ok := err.(ThrownError); ok && te.pos == (Position{}) {
+		te.pos, te.end = fc.Pos, fc.End
+		return nil, te
+	}
+
+	return result, err
 }
 
 func (fc FunctionCall) Computable(ctx Context) bool {
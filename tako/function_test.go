@@ -0,0 +1,130 @@
+package tako
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestClosure(t *testing.T) {
+	testEval(t, []evalTest{
+		{
+			"independent counters",
+			`
+			counter := (){
+				count := 0
+				(){
+					count = count + 1
+					count
+				}
+			}
+			a := counter()
+			b := counter()
+			[a(), a(), a(), b(), a(), b()]
+			`,
+			"[1, 2, 3, 1, 4, 2]",
+		},
+		{
+			"captured argument",
+			`
+			adder := (x){ (y){ x + y } }
+			add5 := adder(5)
+			x := 100
+			add5(1)
+			`,
+			"6",
+		},
+		{
+			"shadowed in caller",
+			`
+			make := (){
+				value := "inner"
+				(){ value }
+			}
+			get := make()
+			f := (){
+				value := "caller"
+				get()
+			}
+			f()
+			`,
+			"inner",
+		},
+		{
+			"callbacks in object",
+			`
+			adder := (x){ (y){ x + y } }
+			[adder(1), adder(2), adder(3)].for((f){ f(10) })
+			`,
+			"[11, 12, 13]",
+		},
+		{
+			"nested fizzbuzz loop",
+			`
+			fizzbuzz := (n){
+				result := []
+				loop := (i){
+					result.push(if i % 15 == 0 {
+						"fizzbuzz"
+					} else if i % 3 == 0 {
+						"fizz"
+					} else if i % 5 == 0 {
+						"buzz"
+					} else {
+						i
+					})
+					if i < n {
+						loop(i + 1)
+					}
+				}
+				loop(1)
+				result
+			}
+			fizzbuzz(15)
+			`,
+			"[1, 2, 'fizz', 4, 'buzz', 'fizz', 7, 8, 'fizz', 'buzz', 11, 'fizz', 13, 14, 'fizzbuzz']",
+		},
+		{
+			"loop variables captured by closures",
+			`
+			fs := []
+			for i in [1, 2, 3] {
+				for j in [10, 20] {
+					fs.push((){ i * j })
+				}
+			}
+			fs.for((f){ f() })
+			`,
+			"[10, 20, 20, 40, 30, 60]",
+		},
+	})
+}
+
+func TestTailCall(t *testing.T) {
+	defer debug.SetMaxStack(debug.SetMaxStack(4 << 20))
+
+	testEval(t, []evalTest{
+		{
+			"self recursion",
+			`
+			count := (i, acc){
+				if i == 0 {
+					acc
+				} else {
+					count(i - 1, acc + 1)
+				}
+			}
+			count(100000, 0)
+			`,
+			"100000",
+		},
+		{
+			"mutual recursion",
+			`
+			even := (n){ if n == 0 { true } else { odd(n - 1) } }
+			odd := (n){ if n == 0 { false } else { even(n - 1) } }
+			[even(100000), odd(100001)]
+			`,
+			"[true, true]",
+		},
+	})
+}
@@ -0,0 +1,49 @@
+package tako
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Interpolation struct {
+	Parts []Expression
+	Pos   Position
+	End   Position
+
+	raw string
+}
+
+func (i Interpolation) String() string {
+	ss := make([]string, len(i.Parts))
+	for j, x := range i.Parts {
+		ss[j] = fmt.Sprint(x)
+	}
+	return fmt.Sprintf("interpolate(%s)", strings.Join(ss, ", "))
+}
+
+func (i Interpolation) Compute(ctx Context) (Expression, error) {
+	ss := make([]string, len(i.Parts))
+	for j, x := range i.Parts {
+		value, err := ctx.ComputeRecursive(x)
+		if err != nil {
+			return nil, err
+		}
+		ss[j] = display(value)
+	}
+	return String(strings.Join(ss, "")), nil
+}
+
+func (i Interpolation) Computable(ctx Context) bool {
+	return true
+}
+
+func (i Interpolation) Position() Position {
+	return i.Pos
+}
+
+func display(value Expression) string {
+	if s, ok := value.(String); ok {
+		return string(s)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,25 @@
+package tako
+
+import (
+	"testing"
+)
+
+func TestInterpolation(t *testing.T) {
+	testEval(t, []evalTest{
+		{src: `name := "tako"; "hello, ${name}!"`, output: "hello, tako!"},
+		{src: `"${1 + 2} and ${[1, "a"]}"`, output: "3 and [1, 'a']"},
+		{src: `'${1}'`, output: "1"},
+		{src: `"${ [a: 1].a }"`, output: "1"},
+		{src: `"${if true { "yes" } else { "no" }}"`, output: "yes"},
+		{src: `"${"inner ${1 + 1}"}"`, output: "inner 2"},
+		{src: `"\${name}"`, output: "${name}"},
+		{src: `"$name costs $${2}"`, output: "$name costs $2"},
+		{src: "`raw ${1}`", output: "raw ${1}"},
+	})
+
+	testEvalError(t, []evalTest{
+		{src: `"${x}"`, output: "NotDefinedError: x is not defined"},
+		{src: `"${}"`, output: `SyntaxError: syntax error near "${}"`},
+		{src: `"${1 +}"`, output: `SyntaxError: syntax error near "+"`},
+	})
+}
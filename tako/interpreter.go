@@ -0,0 +1,204 @@
+package tako
+
+import (
+	"io"
+	"os"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/macrat/simplexer"
+)
+
+func Parse(reader io.Reader, filename string) ([]SyntaxError, Expression) {
+	errs, expr, _ := ParseWithComments(reader, filename)
+	return errs, expr
+}
+
+func ParseWithComments(reader io.Reader, filename string) ([]SyntaxError, Expression, []Comment) {
+	l := NewLexer(reader)
+	l.Filename = filename
+
+	yyParse(l)
+
+	if len(l.errors) == 0 && l.result != nil {
+		l.errors = misplacedControls(l.result, false, false)
+	}
+
+	if len(l.errors) > 0 {
+		return l.errors, nil, l.comments
+	}
+
+	return nil, l.result, l.comments
+}
+
+type Interpreter struct {
+	ctx Context
+
+	UseVM      bool
+	SearchPath []string
+	Stdout     io.Writer
+
+	DecimalPrecision int
+	DecimalRounding  string
+
+	modules   map[string]*Object
+	importing []string
+}
+
+func NewInterpreter() *Interpreter {
+	in := &Interpreter{
+		ctx:     NewContext(),
+		modules: make(map[string]*Object),
+		Stdout:  os.Stdout,
+
+		DecimalPrecision: defaultDecimalPrecision,
+		DecimalRounding:  defaultDecimalRounding,
+	}
+	in.ctx.interpreter = in
+
+	return in
+}
+
+func (in *Interpreter) Context() Context {
+	return in.ctx
+}
+
+func (in *Interpreter) Run(expr Expression) (result Expression, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = InternalError{value: r, stack: debug.Stack()}
+		}
+	}()
+
+	return in.run(in.ctx, expr)
+}
+
+func (in *Interpreter) run(ctx Context, expr Expression) (Expression, error) {
+	if in.UseVM {
+		proto, err := Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		return NewVM(ctx).Run(proto)
+	}
+
+	return ctx.ComputeRecursive(expr)
+}
+
+func (c Context) stdout() io.Writer {
+	if c.interpreter == nil || c.interpreter.Stdout == nil {
+		return os.Stdout
+	}
+	return c.interpreter.Stdout
+}
+
+func (in *Interpreter) EvalReader(reader io.Reader, filename string) (Expression, error) {
+	errs, expr := Parse(reader, filename)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	return in.Run(expr)
+}
+
+func (in *Interpreter) Eval(src string) (Expression, error) {
+	return in.EvalReader(strings.NewReader(src), "eval")
+}
+
+func (in *Interpreter) EvalFile(path string) (Expression, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return in.EvalReader(file, path)
+}
+
+func (in *Interpreter) Set(name string, value Expression) error {
+	key := NewIdentifier(name)
+
+	if _, ok := in.ctx.values[name]; ok {
+		return in.ctx.Put(key, value)
+	}
+
+	return in.ctx.Define(key, value)
+}
+
+func (in *Interpreter) Get(name string) (Expression, error) {
+	return in.ctx.GetByString(name)
+}
+
+func (in *Interpreter) Call(function Expression, arguments ...Expression) (result Expression, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = InternalError{value: r, stack: debug.Stack()}
+		}
+	}()
+
+	site := callSite{function: function, argc: len(arguments), pos: Position{Filename: "embed"}}
+
+	f, err := in.ctx.ComputeRecursive(function)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewVM(in.ctx).callFunction(in.ctx, site, f, arguments)
+}
+
+func (in *Interpreter) Names() []string {
+	return in.ctx.Names()
+}
+
+func MethodNames() []string {
+	names := make([]string, 0, len(builtinMethods)+len(stringMethods))
+	for k := range builtinMethods {
+		names = append(names, k)
+	}
+	for k := range stringMethods {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func IsIncomplete(src string) bool {
+	scanner := newScanner([]byte(src))
+	depth := 0
+	var last *simplexer.Token
+
+	for {
+		token, err := scanner.Scan()
+		if err != nil || token == nil {
+			return depth > 0
+		}
+
+		if last != nil && (last.Literal == `""` || last.Literal == "''") && strings.HasPrefix(token.Literal, last.Literal[:1]) && adjacent(last, token) {
+			return true
+		}
+
+		if token.Type.GetID() == 0 {
+			switch token.Literal {
+			case "{", "(", "[":
+				depth++
+			case "}", ")", "]":
+				depth--
+			case "\"", "'", "`":
+				return true
+			case "*":
+				if last != nil && last.Literal == "/" && adjacent(last, token) {
+					return true
+				}
+			}
+		}
+
+		last = token
+	}
+}
+
+func adjacent(a, b *simplexer.Token) bool {
+	return a.Position.Line == b.Position.Line && a.Position.Column+utf8.RuneCountInString(a.Literal) == b.Position.Column
+}
@@ -0,0 +1,102 @@
+package tako
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEmbedding(t *testing.T) {
+	for _, e := range engines {
+		in := e.interpreter()
+
+		if err := in.Set("base", Integer(10)); err != nil {
+			t.Fatalf("%s: %s", e.name, err)
+		}
+		if err := in.Set("base", Integer(20)); err != nil {
+			t.Fatalf("%s: failed to overwrite: %s", e.name, err)
+		}
+
+		_, err := in.Eval(`
+			add := (x){ base + x }
+			counter := (){
+				count := 0
+				(){
+					count = count + 1
+					count
+				}
+			}()
+			twice := (obj){ obj.n * 2 }
+		`)
+		if err != nil {
+			t.Fatalf("%s: %s", e.name, err)
+		}
+
+		add, err := in.Get("add")
+		if err != nil {
+			t.Fatalf("%s: %s", e.name, err)
+		}
+		if result, err := in.Call(add, Integer(1)); err != nil || result != Integer(21) {
+			t.Errorf("%s: expected add(1) to be 21 but got %v (%v)", e.name, result, err)
+		}
+
+		counter, _ := in.Get("counter")
+		in.Call(counter)
+		if result, err := in.Call(counter); err != nil || result != Integer(2) {
+			t.Errorf("%s: expected counter to keep its state but got %v (%v)", e.name, result, err)
+		}
+
+		obj := NewObject()
+		obj.Named["n"] = Integer(2)
+		twice, _ := in.Get("twice")
+		if result, err := in.Call(twice, obj); err != nil || result != Integer(4) {
+			t.Errorf("%s: expected twice(obj) to be 4 but got %v (%v)", e.name, result, err)
+		}
+
+		plus, _ := in.Get(":+:")
+		if result, err := in.Call(plus, Integer(40), Integer(2)); err != nil || result != Integer(42) {
+			t.Errorf("%s: expected builtin to be callable but got %v (%v)", e.name, result, err)
+		}
+
+		if _, err := in.Call(add); !errors.As(err, new(MissmatchArgumentError)) {
+			t.Errorf("%s: expected MissmatchArgumentError but got %v", e.name, err)
+		}
+		if _, err := in.Get("undefined"); !errors.As(err, new(NotDefinedError)) {
+			t.Errorf("%s: expected NotDefinedError but got %v", e.name, err)
+		}
+	}
+}
+
+func TestIsIncomplete(t *testing.T) {
+	tests := []struct {
+		src        string
+		incomplete bool
+	}{
+		{"1 + 2", false},
+		{"f := (x){", true},
+		{"f := (x){\n\tx + 1\n}", false},
+		{"[1, 2,", true},
+		{"print(", true},
+		{"x := `{`", false},
+		{"x := `abc", true},
+		{"x := '''it's", true},
+		{`x := "" "a"`, false},
+		{"x := '''it's'''", false},
+		{"x := '''\n\tit's", true},
+		{`x := """a "quoted" {`, true},
+		{"x := \"\"\"\n\ta \"quoted\" {\n\t\"\"\"", false},
+		{`x := "\"{"`, false},
+		{`x := "abc`, true},
+		{`x := 'it\'s'`, false},
+		{`"${f({})}"`, false},
+		{"// {", false},
+		{"/* {", true},
+		{"/* { */", false},
+		{"1 / *x", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsIncomplete(tt.src); got != tt.incomplete {
+			t.Errorf("%q: expected %v but got %v", tt.src, tt.incomplete, got)
+		}
+	}
+}
@@ -0,0 +1,497 @@
+package tako
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/macrat/simplexer"
+)
+
+const (
+	interpolationPattern = `\$\{(?:[^{}]|\{(?:[^{}]|\{[^{}]*\})*\})*\}`
+	numberPattern        = `0[xX][0-9a-fA-F](?:_?[0-9a-fA-F])*|0[oO][0-7](?:_?[0-7])*|0[bB][01](?:_?[01])*|[0-9](?:_?[0-9])*(?:\.[0-9](?:_?[0-9])*)?(?:[eE][+-]?[0-9](?:_?[0-9])*)?d?`
+)
+
+type Position struct {
+	simplexer.Position
+
+	Filename string
+
+	source *source
+}
+
+type source struct {
+	lines []string
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line+1, p.Column+1)
+}
+
+func (p Position) SourceLine() string {
+	if p.source == nil || p.Line < 0 || p.Line >= len(p.source.lines) {
+		return ""
+	}
+	return strings.TrimRight(p.source.lines[p.Line], "\r")
+}
+
+func (p Position) advance(s string) Position {
+	for _, r := range s {
+		if r == '\n' {
+			p.Line++
+			p.Column = 0
+		} else {
+			p.Column++
+		}
+	}
+	return p
+}
+
+type Lexer struct {
+	lexer        *simplexer.Lexer
+	result       Expression
+	lastToken    *simplexer.Token
+	lastPosition Position
+	lastLine     string
+	errors       []SyntaxError
+	comments     []Comment
+	afterComment bool
+	source       *source
+	base         *Position
+	Filename     string
+}
+
+type Comment struct {
+	Text     string
+	Pos      Position
+	Trailing bool
+}
+
+func (c Comment) EndLine() int {
+	return c.Pos.Line + strings.Count(c.Text, "\n")
+}
+
+func NewLexer(reader io.Reader) *Lexer {
+	src, _ := ioutil.ReadAll(reader)
+
+	return &Lexer{
+		lexer:  newScanner(src),
+		source: &source{lines: strings.Split(string(src), "\n")},
+	}
+}
+
+func newScanner(src []byte) *simplexer.Lexer {
+	l := simplexer.NewLexer(bytes.NewReader(src))
+
+	l.Whitespace = simplexer.NewPatternTokenType(-1, []string{" ", "\t"})
+	l.TokenTypes = []simplexer.TokenType{
+		simplexer.NewRegexpTokenType(NEWLINE, `[\n\r]+`),
+		simplexer.NewRegexpTokenType(COMMENT, `//[^\n\r]*|/\*(?s:.*?)\*/`),
+		simplexer.NewRegexpTokenType(NUMBER, numberPattern),
+		simplexer.NewPatternTokenType(SHIFT_OPERATOR, []string{"<<", ">>"}),
+		simplexer.NewRegexpTokenType(COMPARE_OPERATOR, `(?:[=!]=|>=?|<=?)`),
+		simplexer.NewPatternTokenType(DEFINE_OPERATOR, []string{":=", "="}),
+		simplexer.NewPatternTokenType(CALCULATE_DEFINE_OPERATOR, []string{"+=", "-=", "*=", "/="}),
+		simplexer.NewPatternTokenType(AND_OPERATOR, []string{"&&"}),
+		simplexer.NewPatternTokenType(OR_OPERATOR, []string{"||"}),
+		simplexer.NewPatternTokenType(FUNCTION_SEP, []string{"){"}),
+		simplexer.NewPatternTokenType(IF, []string{"if"}),
+		simplexer.NewPatternTokenType(ELSE, []string{"else"}),
+		simplexer.NewRegexpTokenType(TRY, `try\b`),
+		simplexer.NewRegexpTokenType(CATCH, `catch\b`),
+		simplexer.NewRegexpTokenType(NOT, `not\b`),
+		simplexer.NewRegexpTokenType(XOR, `xor\b`),
+		simplexer.NewRegexpTokenType(DIV, `div\b`),
+		simplexer.NewRegexpTokenType(WHILE, `while\b`),
+		simplexer.NewRegexpTokenType(FOR, `for\b`),
+		simplexer.NewRegexpTokenType(IN, `in\b`),
+		simplexer.NewRegexpTokenType(BREAK, `break\b`),
+		simplexer.NewRegexpTokenType(CONTINUE, `continue\b`),
+		simplexer.NewRegexpTokenType(RETURN, `return\b`),
+		simplexer.NewPatternTokenType(ELLIPSIS, []string{"..."}),
+		simplexer.NewRegexpTokenType(STRING, `"""(?:\\(?s:.)|`+interpolationPattern+`|(?s:[^\\]))*?"""|'''(?:\\(?s:.)|`+interpolationPattern+`|(?s:[^\\]))*?'''|`+"`[^`]*`"+`|"(?:\\(?s:.)|`+interpolationPattern+`|[^"\\])*"|'(?:\\(?s:.)|`+interpolationPattern+`|[^'\\])*'`),
+		simplexer.NewRegexpTokenType(IDENTIFIER, `[a-zA-Z_][a-zA-Z0-9_]*|:[^ \t\n\r]:|[^ \t\n\r]:`),
+		simplexer.NewRegexpTokenType(0, `.`),
+	}
+
+	return l
+}
+
+func (l *Lexer) Lex(lval *yySymType) int {
+	if len(l.errors) > 0 {
+		return -1
+	}
+
+	line := l.lexer.GetLastLine()
+
+	token, err := l.lexer.Scan()
+	if err != nil {
+		e, ok := err.(simplexer.UnknownTokenError)
+		if !ok {
+			l.Error(err.Error())
+			return -1
+		}
+
+		pos := l.position(e.Position)
+		if l.base != nil {
+			line = pos.SourceLine()
+		}
+
+		l.errors = append(l.errors, SyntaxError{
+			pos:     pos,
+			literal: e.Literal,
+			line:    line,
+		})
+		return -1
+	}
+	if token == nil {
+		return -1
+	}
+
+	tokenID := int(token.Type.GetID())
+	if tokenID == 0 {
+		tokenID = int(token.Literal[0])
+	}
+
+	pos := l.position(token.Position)
+	if l.base != nil {
+		line = pos.SourceLine()
+	}
+
+	if tokenID == COMMENT {
+		comment := Comment{
+			Text:     token.Literal,
+			Pos:      pos,
+			Trailing: l.lastToken != nil && int(l.lastToken.Type.GetID()) != NEWLINE && l.lastPosition.Line == pos.Line,
+		}
+		l.comments = append(l.comments, comment)
+		l.afterComment = !comment.Trailing
+		return l.Lex(lval)
+	}
+	if tokenID == NEWLINE && l.afterComment {
+		l.afterComment = false
+		return l.Lex(lval)
+	}
+	l.afterComment = false
+
+	lval.token = Token{
+		Token:   tokenID,
+		Literal: token.Literal,
+		Pos:     pos,
+		End:     pos.advance(token.Literal),
+	}
+
+	switch tokenID {
+	case NUMBER:
+		if _, err := parseNumber(token.Literal); err != nil {
+			l.errors = append(l.errors, SyntaxError{
+				pos:     pos,
+				literal: token.Literal,
+				line:    line,
+			})
+			return -1
+		}
+	case CALCULATE_DEFINE_OPERATOR:
+		lval.token.Literal = strings.TrimSuffix(token.Literal, "=")
+	case STRING:
+		expr, err := l.stringLiteral(token.Literal, pos, lval.token.End)
+		if err != nil {
+			l.errors = append(l.errors, err...)
+			return -1
+		}
+		lval.expr = expr
+	}
+
+	l.lastToken = token
+	l.lastPosition = pos
+	l.lastLine = line
+
+	return tokenID
+}
+
+func (l *Lexer) position(p simplexer.Position) Position {
+	if l.base != nil {
+		if p.Line == 0 {
+			p.Column += l.base.Column
+		}
+		p.Line += l.base.Line
+	}
+
+	return Position{
+		Position: p,
+		Filename: l.Filename,
+		source:   l.source,
+	}
+}
+
+func (l *Lexer) stringLiteral(literal string, pos, end Position) (Expression, []SyntaxError) {
+	var parts []Expression
+	var errs []SyntaxError
+	raw := ""
+
+	switch {
+	case literal[0] == '`':
+		parts, raw = []Expression{String(literal[1 : len(literal)-1])}, literal
+	case strings.HasPrefix(literal, `"""`) || strings.HasPrefix(literal, "'''"):
+		parts, errs = l.multiline(literal[3:len(literal)-3], pos.advance(literal[:3]))
+		raw = literal
+	default:
+		parts, errs = l.interpolate(literal[1:len(literal)-1], pos.advance(literal[:1]), 0)
+		if strings.Contains(literal, `\x`) || strings.Contains(literal, `\u`) {
+			raw = literal
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+
+	if len(parts) == 1 {
+		if s, ok := parts[0].(String); ok {
+			return Literal{Value: s, Pos: pos, End: end, raw: raw}, nil
+		}
+	}
+
+	return Interpolation{Parts: parts, Pos: pos, End: end, raw: literal}, nil
+}
+
+func (l *Lexer) multiline(s string, pos Position) ([]Expression, []SyntaxError) {
+	first := strings.IndexByte(s, '\n')
+	if first < 0 || strings.TrimSpace(s[:first]) != "" {
+		return l.interpolate(s, pos, 0)
+	}
+	pos = pos.advance(s[:first+1])
+	s = s[first+1:]
+
+	indent := -1
+	lines := strings.Split(s, "\n")
+	if last := lines[len(lines)-1]; strings.TrimSpace(last) == "" {
+		s = s[:len(s)-len(last)]
+		lines = lines[:len(lines)-1]
+		indent = len(last)
+	}
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if n := len(line) - len(strings.TrimLeft(line, " \t")); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+
+	if indent < 0 {
+		indent = 0
+	}
+	return l.interpolate(s, pos, indent)
+}
+
+func (l *Lexer) interpolate(s string, pos Position, indent int) ([]Expression, []SyntaxError) {
+	var parts []Expression
+	var text []byte
+
+	skip := indent
+	for i := 0; i < len(s); i++ {
+		if skip > 0 && (s[i] == ' ' || s[i] == '\t') {
+			skip--
+			continue
+		}
+		skip = 0
+
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			x, n, ok := unescape(s[i+1:])
+			if !ok {
+				start := pos.advance(s[:i])
+				return nil, []SyntaxError{{pos: start, literal: s[i : i+1+n], line: start.SourceLine()}}
+			}
+			text = append(text, x...)
+			i += n
+
+		case strings.HasPrefix(s[i:], "${"):
+			depth, end := 0, i+2
+			for ; end < len(s) && (depth > 0 || s[end] != '}'); end++ {
+				if s[end] == '{' {
+					depth++
+				} else if s[end] == '}' {
+					depth--
+				}
+			}
+			if end == len(s) {
+				start := pos.advance(s[:i])
+				return nil, []SyntaxError{{pos: start, literal: s[i:], line: start.SourceLine()}}
+			}
+			if strings.TrimSpace(s[i+2:end]) == "" {
+				start := pos.advance(s[:i])
+				return nil, []SyntaxError{{pos: start, literal: s[i : end+1], line: start.SourceLine()}}
+			}
+
+			expr, errs := l.embedded(s[i+2:end], pos.advance(s[:i+2]))
+			if errs != nil {
+				return nil, errs
+			}
+
+			if len(text) > 0 || len(parts) == 0 {
+				parts = append(parts, String(text))
+			}
+			parts = append(parts, expr)
+			text = nil
+			i = end
+
+		default:
+			text = append(text, s[i])
+			if s[i] == '\n' {
+				skip = indent
+			}
+		}
+	}
+
+	if len(text) > 0 || len(parts) == 0 {
+		parts = append(parts, String(text))
+	}
+
+	return parts, nil
+}
+
+func (l *Lexer) embedded(src string, pos Position) (Expression, []SyntaxError) {
+	sub := NewLexer(strings.NewReader(src))
+	sub.Filename = l.Filename
+	sub.source = l.source
+	sub.base = &pos
+
+	yyParse(sub)
+
+	if len(sub.errors) > 0 {
+		return nil, sub.errors
+	}
+
+	if list, ok := sub.result.(ExpressionList); ok && len(list) == 1 {
+		return list[0], nil
+	}
+	return sub.result, nil
+}
+
+func unescape(s string) (string, int, bool) {
+	switch s[0] {
+	case 'n':
+		return "\n", 1, true
+	case 'r':
+		return "\r", 1, true
+	case 't':
+		return "\t", 1, true
+	case '\\', '"', '\'', '$':
+		return s[:1], 1, true
+
+	case 'x':
+		if len(s) < 3 {
+			return "", len(s), false
+		}
+		n, err := strconv.ParseUint(s[1:3], 16, 8)
+		if err != nil {
+			return "", 3, false
+		}
+		return string(rune(n)), 3, true
+
+	case 'u':
+		end := strings.IndexByte(s, '}')
+		if !strings.HasPrefix(s, "u{") || end < 0 {
+			return "", 1, false
+		}
+		n, err := strconv.ParseUint(s[2:end], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(n)) {
+			return "", end + 1, false
+		}
+		return string(rune(n)), end + 1, true
+	}
+
+	return "\\" + s[:1], 1, true
+}
+
+func parseNumber(literal string) (Expression, error) {
+	s := strings.Replace(literal, "_", "", -1)
+
+	if len(s) > 2 && s[0] == '0' && strings.ContainsRune("xXoObB", rune(s[1])) {
+		return parseInteger(s, 0)
+	}
+
+	if strings.HasSuffix(s, "d") {
+		return ParseDecimal(strings.TrimSuffix(s, "d"))
+	}
+
+	if !strings.ContainsAny(s, ".eE") {
+		return parseInteger(s, 10)
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	return Float(f), err
+}
+
+func parseInteger(s string, base int) (Expression, error) {
+	if n, err := strconv.ParseInt(s, base, 64); err == nil {
+		return Integer(n), nil
+	}
+
+	n, ok := new(big.Int).SetString(s, base)
+	if !ok {
+		return nil, strconv.ErrSyntax
+	}
+	return normalize(n), nil
+}
+
+func (l *Lexer) docComment(pos Position) string {
+	var lines []string
+
+	expect := pos.Line - 1
+	for i := len(l.comments) - 1; i >= 0; i-- {
+		c := l.comments[i]
+		if c.Pos.Line >= pos.Line {
+			continue
+		}
+		if c.Trailing || c.EndLine() != expect {
+			break
+		}
+
+		lines = append(commentText(c.Text), lines...)
+		expect = c.Pos.Line - 1
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func commentText(text string) []string {
+	if strings.HasPrefix(text, "//") {
+		return []string{strings.TrimPrefix(strings.TrimPrefix(text, "//"), " ")}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/"), "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "*") {
+			line = strings.TrimPrefix(strings.TrimPrefix(line, "*"), " ")
+		}
+		lines[i] = line
+	}
+
+	return lines
+}
+
+func (l *Lexer) Error(e string) {
+	if len(l.errors) > 0 {
+		return
+	}
+
+	err := SyntaxError{
+		pos:  l.lastPosition,
+		line: l.lastLine,
+	}
+	if l.lastToken != nil {
+		err.literal = l.lastToken.Literal
+	}
+
+	l.errors = append(l.errors, err)
+}
@@ -0,0 +1,65 @@
+package tako
+
+import "fmt"
+
+type Logical struct {
+	Operator string
+	Left     Expression
+	Right    Expression
+	Pos      Position
+	End      Position
+}
+
+func (l Logical) String() string {
+	if l.Left == nil {
+		return fmt.Sprintf("%s(%s)", l.Operator, l.Right)
+	}
+	return fmt.Sprintf("%s(%s, %s)", l.Operator, l.Left, l.Right)
+}
+
+func (l Logical) Compute(ctx Context) (Expression, error) {
+	if l.Left == nil {
+		x, err := l.operand(ctx, l.Right)
+		if err != nil {
+			return nil, err
+		}
+		return !x, nil
+	}
+
+	x, err := l.operand(ctx, l.Left)
+	if err != nil {
+		return nil, err
+	}
+
+	if bool(x) == (l.Operator == "||") {
+		return x, nil
+	}
+
+	return l.operand(ctx, l.Right)
+}
+
+func (l Logical) operand(ctx Context, expr Expression) (Boolean, error) {
+	value, err := ctx.ComputeRecursive(expr)
+	if err != nil {
+		return false, err
+	}
+
+	b, ok := value.(Boolean)
+	if !ok {
+		return false, l.typeError(expr)
+	}
+
+	return b, nil
+}
+
+func (l Logical) typeError(operand Expression) ConditionTypeError {
+	return Condition{Condition: operand, Pos: l.Pos, End: l.End}.typeError()
+}
+
+func (l Logical) Computable(ctx Context) bool {
+	return true
+}
+
+func (l Logical) Position() Position {
+	return l.Pos
+}
@@ -0,0 +1,36 @@
+package tako
+
+import (
+	"testing"
+)
+
+func TestShortCircuit(t *testing.T) {
+	testEval(t, []evalTest{
+		{src: `true && (false || true)`, output: "true"},
+		{src: `false || false`, output: "false"},
+		{src: `not true || not false`, output: "true"},
+		{src: `false && undefined`, output: "false"},
+		{src: `true || undefined`, output: "true"},
+		{src: `false && 1`, output: "false"},
+		{
+			"right side runs only when needed",
+			`
+			calls := 0
+			f := (x){
+				calls = calls + 1
+				x
+			}
+			[false && f(true), true || f(false), true && f(true), false || f(false), calls]
+			`,
+			"[false, true, true, false, 2]",
+		},
+	})
+
+	testEvalError(t, []evalTest{
+		{src: `1 && true`, output: "ConditionTypeError: condition value must be boolean value"},
+		{src: `true && 1`, output: "ConditionTypeError: condition value must be boolean value"},
+		{src: `false || "a"`, output: "ConditionTypeError: condition value must be boolean value"},
+		{src: `not 1`, output: "ConditionTypeError: condition value must be boolean value"},
+		{src: `true && undefined`, output: "NotDefinedError: undefined is not defined"},
+	})
+}
@@ -0,0 +1,195 @@
+package tako
+
+import "fmt"
+
+type While struct {
+	Condition Expression
+	Body      Expression
+	Pos       Position
+	End       Position
+}
+
+func (w While) String() string {
+	return fmt.Sprintf("while(%s, {%s})", w.Condition, w.Body)
+}
+
+func (w While) Compute(ctx Context) (Expression, error) {
+	result := NewObject()
+
+	for {
+		cond, err := ctx.ComputeRecursive(w.Condition)
+		if err != nil {
+			return nil, err
+		}
+
+		if b, ok := cond.(Boolean); !ok {
+			return nil, w.typeError()
+		} else if !b {
+			return result, nil
+		}
+
+		if stop, err := iterate(ctx.MakeScope(), w.Body, result); err != nil {
+			return nil, err
+		} else if stop {
+			return result, nil
+		}
+	}
+}
+
+func (w While) Computable(ctx Context) bool {
+	return true
+}
+
+func (w While) Position() Position {
+	return w.Pos
+}
+
+func (w While) typeError() ConditionTypeError {
+	return Condition{Condition: w.Condition, Pos: w.Pos, End: w.End}.typeError()
+}
+
+type For struct {
+	Variable Identifier
+	Iterable Expression
+	Body     Expression
+	Pos      Position
+	End      Position
+}
+
+func (f For) String() string {
+	return fmt.Sprintf("for(%s, (%s){%s})", f.Iterable, f.Variable, f.Body)
+}
+
+func (f For) Compute(ctx Context) (Expression, error) {
+	iterable, err := ctx.ComputeRecursive(f.Iterable)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, ok := iterable.(*Object)
+	if !ok {
+		return nil, f.typeError(iterable)
+	}
+
+	result := NewObject()
+
+	for i := 0; i < len(obj.Indexed); i++ {
+		scope := ctx.MakeScope()
+		if err := scope.Define(f.Variable, obj.Indexed[i]); err != nil {
+			return nil, err
+		}
+
+		if stop, err := iterate(scope, f.Body, result); err != nil {
+			return nil, err
+		} else if stop {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+func (f For) Computable(ctx Context) bool {
+	return true
+}
+
+func (f For) Position() Position {
+	return f.Pos
+}
+
+func (f For) typeError(value Expression) TypeError {
+	start, end, ok := spanOf(f.Iterable)
+	if !ok {
+		start, end = f.Pos, f.End
+	}
+
+	return TypeError{
+		name:    "iterable of for",
+		excepts: []string{"object"},
+		got:     typeName(value),
+		pos:     start,
+		end:     end,
+	}
+}
+
+type Break struct {
+	Value Expression
+	Pos   Position
+	End   Position
+}
+
+func (b Break) String() string {
+	if b.Value == nil {
+		return "break()"
+	}
+	return fmt.Sprintf("break(%s)", b.Value)
+}
+
+func (b Break) Compute(ctx Context) (Expression, error) {
+	if b.Value == nil {
+		return nil, loopSignal{breaking: true}
+	}
+
+	value, err := ctx.ComputeRecursive(b.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, loopSignal{breaking: true, value: value}
+}
+
+func (b Break) Computable(ctx Context) bool {
+	return true
+}
+
+func (b Break) Position() Position {
+	return b.Pos
+}
+
+type Continue struct {
+	Pos Position
+	End Position
+}
+
+func (c Continue) String() string {
+	return "continue()"
+}
+
+func (c Continue) Compute(ctx Context) (Expression, error) {
+	return nil, loopSignal{}
+}
+
+func (c Continue) Computable(ctx Context) bool {
+	return true
+}
+
+func (c Continue) Position() Position {
+	return c.Pos
+}
+
+type loopSignal struct {
+	breaking bool
+	value    Expression
+}
+
+func (s loopSignal) Error() string {
+	if s.breaking {
+		return "break is not in loop"
+	}
+	return "continue is not in loop"
+}
+
+func iterate(ctx Context, body Expression, result *Object) (bool, error) {
+	value, err := ctx.ComputeRecursive(body)
+	if s, ok := err.(loopSignal); ok {
+		if s.value != nil {
+			result.Indexed = append(result.Indexed, s.value)
+		}
+		return s.breaking, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	result.Indexed = append(result.Indexed, value)
+	return false, nil
+}
@@ -0,0 +1,24 @@
+package tako
+
+import (
+	"testing"
+)
+
+func TestLoops(t *testing.T) {
+	testEval(t, []evalTest{
+		{src: `for x in [1, 2, 3] { x * 2 }`, output: "[2, 4, 6]"},
+		{src: `i := 0; while i < 3 { i = i + 1 }`, output: "[1, 2, 3]"},
+		{src: `while false { 1 }`, output: "[]"},
+		{src: `for x in [1, 2, 3, 4] { if x == 3 { break }; x }`, output: "[1, 2]"},
+		{src: `for x in [1, 2, 3] { if x == 2 { break(x * 10) }; x }`, output: "[1, 20]"},
+		{src: `for x in [1, 2, 3, 4] { if x % 2 == 0 { continue }; x }`, output: "[1, 3]"},
+		{src: `for x in [1, 2] { for y in [1, 2, 3] { if y == 2 { break }; [x, y] } }`, output: "[[[1, 1]], [[2, 1]]]"},
+	})
+
+	testEvalError(t, []evalTest{
+		{src: `for x in 1 { x }`, output: "TypeError: iterable of for must be object but got integer"},
+		{src: `break`, output: `SyntaxError: syntax error near "break"`},
+		{src: `f := (){ break }`, output: `SyntaxError: syntax error near "break"`},
+		{src: `for x in [1] { f := (){ continue } }`, output: `SyntaxError: syntax error near "continue"`},
+	})
+}
@@ -0,0 +1,150 @@
+package tako
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var builtinModules = map[string]map[string]Expression{
+	"string": stringMethods,
+}
+
+func importFunction(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+	path, err := ctx.ComputeRecursive(args["path"])
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := path.(String)
+	if !ok {
+		return nil, ImportError{
+			path:   fmt.Sprint(path),
+			reason: "path must be string",
+			pos:    ctx.callPosition(),
+			end:    ctx.callEnd(),
+		}
+	}
+
+	if ctx.interpreter == nil {
+		return nil, ImportError{
+			path:   string(s),
+			reason: "import is not available here",
+			pos:    ctx.callPosition(),
+			end:    ctx.callEnd(),
+		}
+	}
+
+	return ctx.interpreter.importModule(ctx, string(s))
+}
+
+func (in *Interpreter) resolveModule(path string, from Position) (name, key string, ok bool) {
+	var candidates []string
+	if filepath.IsAbs(path) {
+		candidates = []string{path}
+	} else {
+		dir := "."
+		if info, err := os.Stat(from.Filename); err == nil && !info.IsDir() {
+			dir = filepath.Dir(from.Filename)
+		}
+		candidates = append(candidates, filepath.Join(dir, path))
+
+		for _, d := range in.SearchPath {
+			candidates = append(candidates, filepath.Join(d, path))
+		}
+	}
+
+	for _, c := range candidates {
+		if info, err := os.Stat(c); err == nil && !info.IsDir() {
+			abs, err := filepath.Abs(c)
+			if err != nil {
+				return "", "", false
+			}
+			return c, abs, true
+		}
+	}
+
+	return "", "", false
+}
+
+func (in *Interpreter) importModule(ctx Context, path string) (Expression, error) {
+	pos, end := ctx.callPosition(), ctx.callEnd()
+
+	if members, ok := builtinModules[path]; ok {
+		module := NewObject()
+		module.module = true
+		for k, v := range members {
+			module.Named[k] = v
+		}
+		return module, nil
+	}
+
+	name, key, ok := in.resolveModule(path, pos)
+	if !ok {
+		return nil, ImportError{path: path, reason: "no such file", pos: pos, end: end}
+	}
+
+	if m, ok := in.modules[key]; ok {
+		return m, nil
+	}
+
+	for i, k := range in.importing {
+		if k == key {
+			chain := make([]string, 0, len(in.importing)-i+1)
+			for _, c := range append(in.importing[i:], key) {
+				chain = append(chain, displayPath(c))
+			}
+			return nil, ImportError{
+				path:   path,
+				reason: "import cycle: " + strings.Join(chain, " -> "),
+				pos:    pos,
+				end:    end,
+			}
+		}
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, ImportError{path: path, reason: err.Error(), pos: pos, end: end}
+	}
+	defer file.Close()
+
+	errs, expr := Parse(file, name)
+	if len(errs) > 0 {
+		return nil, NewTracedError(errs[0], ctx.calleeFrame())
+	}
+
+	in.importing = append(in.importing, key)
+	defer func() {
+		in.importing = in.importing[:len(in.importing)-1]
+	}()
+
+	scope := NewContext()
+	scope.interpreter = in
+	scope.frame = ctx.calleeFrame()
+
+	if _, err := in.run(scope, expr); err != nil {
+		return nil, err
+	}
+
+	exports := NewObject()
+	exports.module = true
+	for k, v := range scope.values {
+		if !strings.HasPrefix(k, "_") {
+			exports.Named[k] = v
+		}
+	}
+	in.modules[key] = exports
+
+	return exports, nil
+}
+
+func displayPath(path string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return path
+}
@@ -0,0 +1,107 @@
+package tako
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestModuleMemberCall(t *testing.T) {
+	testEval(t, []evalTest{
+		{src: `import("string").upper("tako")`, output: "TAKO"},
+		{src: `str := import("string"); str.join(", ", ["a", "b"])`, output: "a, b"},
+		{src: `upper := import("string").upper; upper("tako")`, output: "TAKO"},
+		{src: `obj := [1, 2]; obj.push(3); obj.length()`, output: "3"},
+		{src: `obj := [n: 2, f: (self, x){ self.n * x }]; obj.f(3)`, output: "6"},
+		{src: `obj := [f: (self){ self }]; obj.f() == obj`, output: "true"},
+		{src: `obj := [f: (self, x){ self.f == obj.f && x }]; obj.f(true)`, output: "true"},
+	})
+}
+
+func writeModules(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestImportExports(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"lib.tako":    "value := 1\n_hidden := 2\ndouble := (x){ x * 2 }\n",
+		"main.tako":   "lib := import(\"lib.tako\")\n[lib.value, lib.double(3)]\n",
+		"hidden.tako": "import(\"lib.tako\")._hidden\n",
+	})
+
+	for _, e := range engines {
+		result, err := e.interpreter().EvalFile(filepath.Join(dir, "main.tako"))
+		if err != nil {
+			t.Errorf("%s: %s", e.name, err)
+		} else if output := display(result); output != "[1, 6]" {
+			t.Errorf("%s: expected [1, 6] but got %s", e.name, output)
+		}
+
+		_, err = e.interpreter().EvalFile(filepath.Join(dir, "hidden.tako"))
+		if !errors.As(err, new(NotDefinedError)) {
+			t.Errorf("%s: expected NotDefinedError for unexported name but got %v", e.name, err)
+		}
+	}
+}
+
+func TestImportCache(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"counter.tako": "println(\"loaded\")\ncount := [n: 0]\n",
+		"main.tako":    "a := import(\"counter.tako\")\nb := import(\"./counter.tako\")\na.count.n = 1\nb.count.n\n",
+	})
+
+	for _, e := range engines {
+		var stdout strings.Builder
+		in := e.interpreter()
+		in.Stdout = &stdout
+
+		result, err := in.EvalFile(filepath.Join(dir, "main.tako"))
+		if err != nil {
+			t.Errorf("%s: %s", e.name, err)
+			continue
+		}
+		if output := display(result); output != "1" {
+			t.Errorf("%s: expected both imports to share a module but got %s", e.name, output)
+		}
+		if stdout.String() != "loaded\n" {
+			t.Errorf("%s: expected module to be evaluated once but got output %q", e.name, stdout.String())
+		}
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"a.tako":      "import(\"b.tako\")\n",
+		"b.tako":      "import(\"a.tako\")\n",
+		"bad.tako":    "x := (1 +\n",
+		"syntax.tako": "f := (){\n\timport(\"bad.tako\")\n\tnull\n}\nf()\n",
+	})
+
+	for _, e := range engines {
+		_, err := e.interpreter().EvalFile(filepath.Join(dir, "a.tako"))
+		var ie ImportError
+		if !errors.As(err, &ie) || !strings.Contains(ie.reason, "import cycle: ") {
+			t.Errorf("%s: expected import cycle error but got %v", e.name, err)
+		}
+
+		_, err = e.interpreter().EvalFile(filepath.Join(dir, "syntax.tako"))
+		var te TracedError
+		if !errors.As(err, &te) || !errors.As(err, new(SyntaxError)) {
+			t.Errorf("%s: expected traced syntax error but got %#v", e.name, err)
+			continue
+		}
+		if n := len(te.Stack); n == 0 || te.Stack[n-1].Pos.String() != filepath.Join(dir, "syntax.tako")+":2:2" {
+			t.Errorf("%s: expected traceback to end at the import call but got %v", e.name, te.Stack)
+		}
+	}
+}
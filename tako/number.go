@@ -0,0 +1,344 @@
+package tako
+
+import (
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	bitwiseOperators = map[string]bool{
+		"&": true, "|": true, "xor": true, "<<": true, ">>": true,
+	}
+
+	numberString = regexp.MustCompile(`^[+-]?(?:` + numberPattern + `)$`)
+)
+
+func calculate(ctx Context, op string, x, y Expression) (Expression, error) {
+	op = strings.Trim(op, ":")
+
+	bitwise := bitwiseOperators[op]
+	excepts := "number"
+	if bitwise {
+		excepts = "integer"
+	}
+
+	if !isNumber(x, bitwise) {
+		return nil, TypeError{name: "left operand of " + op, excepts: []string{excepts}, got: typeName(x)}
+	}
+	if !isNumber(y, bitwise) {
+		return nil, TypeError{name: "right operand of " + op, excepts: []string{excepts}, got: typeName(y)}
+	}
+
+	a, aok := x.(Integer)
+	b, bok := y.(Integer)
+	if aok && bok {
+		if bitwise {
+			return calculateBitwise(op, a, b)
+		}
+		return calculateInteger(op, a, b)
+	}
+
+	_, af := x.(Float)
+	_, bf := y.(Float)
+	_, ad := x.(Decimal)
+	_, bd := y.(Decimal)
+	if ad || bd {
+		if af {
+			return nil, TypeError{name: "left operand of " + op, excepts: []string{"integer", "decimal"}, got: typeName(x)}
+		}
+
+		if op == "^" {
+			if !isNumber(y, true) {
+				return nil, TypeError{name: "right operand of ^", excepts: []string{"integer"}, got: typeName(y)}
+			}
+			return powerDecimal(ctx, toDecimal(x), toBig(y))
+		}
+
+		if bf {
+			return nil, TypeError{name: "right operand of " + op, excepts: []string{"integer", "decimal"}, got: typeName(y)}
+		}
+		return calculateDecimal(ctx, op, toDecimal(x), toDecimal(y))
+	}
+
+	if af || bf {
+		return calculateFloat(op, toFloat(x), toFloat(y))
+	}
+
+	if bitwise {
+		return calculateBigBitwise(op, toBig(x), toBig(y))
+	}
+	return calculateBig(op, toBig(x), toBig(y))
+}
+
+func decimalMix(op string, x, y Expression) error {
+	_, af := x.(Float)
+	_, bf := y.(Float)
+	_, ad := x.(Decimal)
+	_, bd := y.(Decimal)
+
+	if af && bd {
+		return TypeError{name: "left operand of " + op, excepts: []string{"integer", "decimal"}, got: typeName(x)}
+	}
+	if ad && bf {
+		return TypeError{name: "right operand of " + op, excepts: []string{"integer", "decimal"}, got: typeName(y)}
+	}
+	return nil
+}
+
+func isNumber(x Expression, integer bool) bool {
+	switch x.(type) {
+	case Integer, BigInt:
+		return true
+	case Float, Decimal:
+		return !integer
+	}
+	return false
+}
+
+func toFloat(x Expression) Float {
+	switch n := x.(type) {
+	case Integer:
+		return Float(n)
+	case BigInt:
+		f, _ := new(big.Float).SetInt(n.value).Float64()
+		return Float(f)
+	case Decimal:
+		f, _ := strconv.ParseFloat(n.String(), 64)
+		return Float(f)
+	}
+	return x.(Float)
+}
+
+func toBig(x Expression) *big.Int {
+	if n, ok := x.(BigInt); ok {
+		return n.value
+	}
+	return big.NewInt(int64(x.(Integer)))
+}
+
+func normalize(x *big.Int) Expression {
+	if x.IsInt64() {
+		return Integer(x.Int64())
+	}
+	return BigInt{value: x}
+}
+
+func calculateInteger(op string, a, b Integer) (Expression, error) {
+	switch op {
+	case "+":
+		if c := a + b; (c > a) == (b > 0) {
+			return c, nil
+		}
+	case "-":
+		if c := a - b; (c < a) == (b > 0) {
+			return c, nil
+		}
+	case "*":
+		if a == 0 || b == 0 {
+			return Integer(0), nil
+		}
+		if c := a * b; c/b == a && !(a == math.MinInt64 && b == -1) {
+			return c, nil
+		}
+	case "/":
+		return Float(a) / Float(b), nil
+	case "div":
+		if b == 0 {
+			return nil, ArithmeticError{reason: "integer division by zero"}
+		}
+		if !(a == math.MinInt64 && b == -1) {
+			return a / b, nil
+		}
+	case "%":
+		if b == 0 {
+			return nil, ArithmeticError{reason: "integer modulo by zero"}
+		}
+		return a % b, nil
+	case "^":
+		if b < 0 {
+			return Float(math.Pow(float64(a), float64(b))), nil
+		}
+	case "<":
+		return Boolean(a < b), nil
+	case "<=":
+		return Boolean(a <= b), nil
+	case ">":
+		return Boolean(a > b), nil
+	case ">=":
+		return Boolean(a >= b), nil
+	}
+
+	return calculateBig(op, big.NewInt(int64(a)), big.NewInt(int64(b)))
+}
+
+func calculateBig(op string, a, b *big.Int) (Expression, error) {
+	switch op {
+	case "+":
+		return normalize(new(big.Int).Add(a, b)), nil
+	case "-":
+		return normalize(new(big.Int).Sub(a, b)), nil
+	case "*":
+		return normalize(new(big.Int).Mul(a, b)), nil
+	case "/":
+		if b.Sign() == 0 {
+			return calculateFloat(op, toFloat(normalize(a)), 0)
+		}
+		f, _ := new(big.Rat).SetFrac(a, b).Float64()
+		return Float(f), nil
+	case "div":
+		if b.Sign() == 0 {
+			return nil, ArithmeticError{reason: "integer division by zero"}
+		}
+		return normalize(new(big.Int).Quo(a, b)), nil
+	case "%":
+		if b.Sign() == 0 {
+			return nil, ArithmeticError{reason: "integer modulo by zero"}
+		}
+		return normalize(new(big.Int).Rem(a, b)), nil
+	case "^":
+		if b.Sign() < 0 {
+			return calculateFloat(op, toFloat(normalize(a)), toFloat(normalize(b)))
+		}
+		return normalize(new(big.Int).Exp(a, b, nil)), nil
+	case "<":
+		return Boolean(a.Cmp(b) < 0), nil
+	case "<=":
+		return Boolean(a.Cmp(b) <= 0), nil
+	case ">":
+		return Boolean(a.Cmp(b) > 0), nil
+	case ">=":
+		return Boolean(a.Cmp(b) >= 0), nil
+	}
+
+	return nil, ArithmeticError{reason: "unsupported operator " + op}
+}
+
+func calculateFloat(op string, a, b Float) (Expression, error) {
+	switch op {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		return a / b, nil
+	case "div":
+		return Float(math.Trunc(float64(a / b))), nil
+	case "%":
+		return Float(math.Mod(float64(a), float64(b))), nil
+	case "^":
+		return Float(math.Pow(float64(a), float64(b))), nil
+	case "<":
+		return Boolean(a < b), nil
+	case "<=":
+		return Boolean(a <= b), nil
+	case ">":
+		return Boolean(a > b), nil
+	case ">=":
+		return Boolean(a >= b), nil
+	}
+
+	return nil, ArithmeticError{reason: "unsupported operator " + op}
+}
+
+func calculateBitwise(op string, a, b Integer) (Expression, error) {
+	switch op {
+	case "&":
+		return a & b, nil
+	case "|":
+		return a | b, nil
+	case "xor":
+		return a ^ b, nil
+	}
+
+	if b < 0 {
+		return nil, ArithmeticError{reason: "negative shift count"}
+	}
+
+	if op == ">>" {
+		return a >> uint64(b), nil
+	}
+	if b < 64 && (a<<uint64(b))>>uint64(b) == a {
+		return a << uint64(b), nil
+	}
+
+	return calculateBigBitwise(op, big.NewInt(int64(a)), big.NewInt(int64(b)))
+}
+
+func calculateBigBitwise(op string, a, b *big.Int) (Expression, error) {
+	switch op {
+	case "&":
+		return normalize(new(big.Int).And(a, b)), nil
+	case "|":
+		return normalize(new(big.Int).Or(a, b)), nil
+	case "xor":
+		return normalize(new(big.Int).Xor(a, b)), nil
+	}
+
+	if b.Sign() < 0 {
+		return nil, ArithmeticError{reason: "negative shift count"}
+	}
+	if !b.IsUint64() || b.Uint64() > math.MaxUint32 {
+		return nil, ArithmeticError{reason: "shift count too large"}
+	}
+
+	if op == ">>" {
+		return normalize(new(big.Int).Rsh(a, uint(b.Uint64()))), nil
+	}
+	return normalize(new(big.Int).Lsh(a, uint(b.Uint64()))), nil
+}
+
+func negate(x Expression) (Expression, bool) {
+	switch n := x.(type) {
+	case Integer:
+		if n == math.MinInt64 {
+			return normalize(new(big.Int).Neg(big.NewInt(int64(n)))), true
+		}
+		return -n, true
+	case BigInt:
+		return normalize(new(big.Int).Neg(n.value)), true
+	case Decimal:
+		return Decimal{value: new(big.Int).Neg(n.value), scale: n.scale}, true
+	case Float:
+		return -n, true
+	}
+	return nil, false
+}
+
+func parseNumberString(s string) (Expression, bool) {
+	s = strings.TrimSpace(s)
+	if !numberString.MatchString(s) {
+		return nil, false
+	}
+
+	negative := s[0] == '-'
+	s = strings.TrimLeft(s, "+-")
+
+	n, err := parseNumber(s)
+	if err != nil {
+		return nil, false
+	}
+
+	if negative {
+		return negate(n)
+	}
+	return n, true
+}
+
+func toIndex(value Expression) (int, bool) {
+	switch n := value.(type) {
+	case Integer:
+		return int(n), true
+	case BigInt:
+		return -1, true
+	case Float:
+		if float64(n) == math.Trunc(float64(n)) {
+			return int(n), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,62 @@
+package tako
+
+import (
+	"testing"
+)
+
+func TestNumberLiterals(t *testing.T) {
+	testEval(t, []evalTest{
+		{src: `[0xff, 0o17, 0b101, 1_000_000, 0xf_f]`, output: "[255, 15, 5, 1000000, 255]"},
+		{src: `[3.14, 1e3, 2.5e-3, 1_000.5, 1E2]`, output: "[3.14, 1000.0, 0.0025, 1000.5, 100.0]"},
+		{src: `1000000.0`, output: "1000000.0"},
+		{src: `1e21`, output: "1e+21"},
+	})
+
+	testEvalError(t, []evalTest{
+		{src: `0x`, output: `SyntaxError: syntax error near "x"`},
+		{src: `0xg`, output: `SyntaxError: syntax error near "xg"`},
+		{src: `0o8`, output: `SyntaxError: syntax error near "o8"`},
+		{src: `0b102`, output: `SyntaxError: syntax error near "2"`},
+		{src: `1__0`, output: `SyntaxError: syntax error near "__0"`},
+		{src: `1_`, output: `SyntaxError: syntax error near "_"`},
+		{src: `1e`, output: `SyntaxError: syntax error near "e"`},
+	})
+}
+
+func TestBigIntPromotion(t *testing.T) {
+	testEval(t, []evalTest{
+		{src: `9223372036854775807 + 1`, output: "9223372036854775808"},
+		{src: `-9223372036854775808 - 1`, output: "-9223372036854775809"},
+		{src: `9223372036854775807 * 2`, output: "18446744073709551614"},
+		{src: `-(-9223372036854775808)`, output: "9223372036854775808"},
+		{src: `2 ^ 64`, output: "18446744073709551616"},
+		{src: `99999999999999999999 div 10`, output: "9999999999999999999"},
+		{src: `integer("12345678901234567890") + 1`, output: "12345678901234567891"},
+		{src: `(2 ^ 64) == 18446744073709551616 && 2 ^ 64 > 1`, output: "true"},
+		{src: `float(2 ^ 70)`, output: "1.1805916207174113e+21"},
+	})
+
+	tests := []struct {
+		src    string
+		bigint bool
+	}{
+		{`9223372036854775807`, false},
+		{`9223372036854775808`, true},
+		{`9223372036854775808 - 1`, false},
+		{`(2 ^ 64) - (2 ^ 64) + 1`, false},
+		{`(2 ^ 64) div (2 ^ 60)`, false},
+	}
+
+	for _, tt := range tests {
+		for _, e := range engines {
+			result, err := e.interpreter().Eval(tt.src)
+			if err != nil {
+				t.Errorf("%s (%s): %s", tt.src, e.name, err)
+				continue
+			}
+			if _, ok := result.(BigInt); ok != tt.bigint {
+				t.Errorf("%s (%s): expected BigInt to be %v but got %T", tt.src, e.name, tt.bigint, result)
+			}
+		}
+	}
+}
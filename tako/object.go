@@ -1,7 +1,8 @@
-package main
+package tako
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -13,7 +14,12 @@ var (
 				return nil, err
 			}
 
-			return Number(len(self.(*Object).Indexed)), nil
+			obj, err := objectArgument(ctx, "self of length", self)
+			if err != nil {
+				return nil, err
+			}
+
+			return Integer(len(obj.Indexed)), nil
 		}, "", "self"),
 
 		"size": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
@@ -22,7 +28,12 @@ var (
 				return nil, err
 			}
 
-			return Number(len(self.(*Object).Indexed) + len(self.(*Object).Named)), nil
+			obj, err := objectArgument(ctx, "self of size", self)
+			if err != nil {
+				return nil, err
+			}
+
+			return Integer(len(obj.Indexed) + len(obj.Named)), nil
 		}, "", "self"),
 
 		"push": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
@@ -36,10 +47,14 @@ var (
 				return nil, err
 			}
 
-			obj := self.(*Object)
+			obj, err := objectArgument(ctx, "self of push", self)
+			if err != nil {
+				return nil, err
+			}
+
 			obj.Indexed = append(obj.Indexed, value)
 
-			return args["self"], nil
+			return obj, nil
 		}, "", "self", "value"),
 
 		"pop": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
@@ -48,10 +63,18 @@ var (
 				return nil, err
 			}
 
-			obj := self.(*Object)
+			obj, err := objectArgument(ctx, "self of pop", self)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(obj.Indexed) == 0 {
+				return nil, OutOfBoundsError{max: 0, got: Integer(-1), pos: ctx.callPosition(), end: ctx.callEnd()}
+			}
+
 			obj.Indexed = obj.Indexed[:len(obj.Indexed)-1]
 
-			return args["self"], nil
+			return obj, nil
 		}, "", "self"),
 
 		"for": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
@@ -60,14 +83,20 @@ var (
 				return nil, err
 			}
 
+			obj, err := objectArgument(ctx, "self of for", self)
+			if err != nil {
+				return nil, err
+			}
+
 			result := NewObject()
 
-			obj := self.(*Object)
 			for _, x := range obj.Indexed {
-				r, err := FunctionCall{
+				r, err := ctx.ComputeRecursive(FunctionCall{
 					Function:  args["func"],
 					Arguments: []Expression{x},
-				}.Compute(ctx)
+					Pos:       ctx.callPosition(),
+					End:       ctx.callEnd(),
+				})
 				if err != nil {
 					return nil, err
 				}
@@ -84,10 +113,19 @@ var (
 				return nil, err
 			}
 
-			result := NewObject()
+			obj, err := objectArgument(ctx, "self of keys", self)
+			if err != nil {
+				return nil, err
+			}
 
-			obj := self.(*Object)
-			for name, _ := range obj.Named {
+			names := make([]string, 0, len(obj.Named))
+			for name := range obj.Named {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			result := NewObject()
+			for _, name := range names {
 				result.Indexed = append(result.Indexed, String(name))
 			}
 
@@ -99,6 +137,17 @@ var (
 type Object struct {
 	Indexed []Expression
 	Named   map[string]Expression
+
+	multiline bool
+	module    bool
+	entries   []objectEntry
+	pos       Position
+	end       Position
+}
+
+type objectEntry struct {
+	key string
+	pos Position
 }
 
 func NewObject() *Object {
@@ -108,13 +157,32 @@ func NewObject() *Object {
 	}
 }
 
+func (o *Object) addIndexed(value Expression) {
+	o.Indexed = append(o.Indexed, value)
+	o.entries = append(o.entries, objectEntry{pos: startOf(value)})
+}
+
+func (o *Object) addNamed(key Identifier, value Expression) {
+	if _, ok := o.Named[key.Key]; !ok {
+		o.entries = append(o.entries, objectEntry{key: key.Key, pos: key.Pos})
+	}
+	o.Named[key.Key] = value
+}
+
 func (o *Object) String() string {
 	ss := make([]string, len(o.Indexed))
 	for i, e := range o.Indexed {
 		ss[i] = fmt.Sprint(e)
 	}
-	for k, v := range o.Named {
-		ss = append(ss, fmt.Sprintf("%s: %s", k, v))
+
+	keys := make([]string, 0, len(o.Named))
+	for k := range o.Named {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		ss = append(ss, fmt.Sprintf("%s: %s", k, o.Named[k]))
 	}
 	return "[" + strings.Join(ss, ", ") + "]"
 }
@@ -126,7 +194,7 @@ func (o *Object) Compute(ctx Context) (Expression, error) {
 	}
 
 	for i, x := range o.Indexed {
-		c, err := x.Compute(ctx)
+		c, err := ctx.ComputeRecursive(x)
 		if err != nil {
 			return nil, err
 		}
@@ -134,7 +202,7 @@ func (o *Object) Compute(ctx Context) (Expression, error) {
 	}
 
 	for k, v := range o.Named {
-		c, err := v.Compute(ctx)
+		c, err := ctx.ComputeRecursive(v)
 		if err != nil {
 			return nil, err
 		}
@@ -160,6 +228,11 @@ func (o *Object) Computable(ctx Context) bool {
 	return false
 }
 
+func isModule(x Expression) bool {
+	obj, ok := x.(*Object)
+	return ok && obj.module
+}
+
 func (o *Object) Get(key Expression) (Expression, error) {
 	switch k := key.(type) {
 	case Identifier:
@@ -177,11 +250,18 @@ func (o *Object) Get(key Expression) (Expression, error) {
 		} else if r, ok = builtinMethods[string(k)]; ok {
 			return r, nil
 		} else {
-			return nil, NotDefinedError(NewIdentifier(string(k)))
+			return nil, KeyError{key: string(k)}
 		}
 
-	case Number:
-		i := int(k)
+	case Integer, BigInt, Float:
+		i, ok := toIndex(k)
+		if !ok {
+			return nil, TypeError{
+				name:    "index of object",
+				excepts: []string{"integer"},
+				got:     typeName(k),
+			}
+		}
 
 		if 0 <= i && i < len(o.Indexed) {
 			return o.Indexed[i], nil
@@ -194,7 +274,7 @@ func (o *Object) Get(key Expression) (Expression, error) {
 
 		return nil, OutOfBoundsError{
 			max: max,
-			got: i,
+			got: k,
 		}
 	}
 
@@ -0,0 +1,73 @@
+package tako
+
+import (
+	"testing"
+)
+
+func TestObjectStringKey(t *testing.T) {
+	testEval(t, []evalTest{
+		{
+			"read named key",
+			`
+			obj := [1, a: 2]
+			obj["a"] + obj.a
+			`,
+			"4",
+		},
+		{
+			"assign existing key",
+			`
+			obj := [a: 1]
+			obj["a"] = 2
+			obj
+			`,
+			"[a: 2]",
+		},
+		{
+			"define new key",
+			`
+			obj := [a: 1]
+			obj["b"] := 2
+			obj.c := 3
+			obj
+			`,
+			"[a: 1, b: 2, c: 3]",
+		},
+		{
+			"read missing key",
+			`
+			obj := [a: 1]
+			try {
+				obj["b"]
+			} catch (e) {
+				[e.kind, e.message, e.position]
+			}
+			`,
+			`['KeyError', 'key \'b\' is not found in object', 'eval:4:5']`,
+		},
+		{
+			"assign missing key",
+			`
+			obj := [a: 1]
+			try {
+				obj["b"] = 2
+			} catch (e) {
+				[e.kind, e.position]
+			}
+			`,
+			"['KeyError', 'eval:4:5']",
+		},
+		{
+			"define existing key",
+			`
+			obj := [a: 1]
+			try {
+				obj["a"] := 2
+			} catch (e) {
+				e.kind
+			}
+			`,
+			"AlreadyDefinedError",
+		},
+	})
+}
@@ -1,2 +1,2 @@
 //go:generate goyacc -o parser_gen.go parser.go.y
-package main
+package tako
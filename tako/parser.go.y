@@ -1,9 +1,5 @@
 %{
-package main
-
-import (
-	"strconv"
-)
+package tako
 
 %}
 
@@ -16,29 +12,40 @@ import (
 	expList   ExpressionList
 	identList []Identifier
 	object    *Object
+	then      conditionBlock
 }
 
-%type<expr>      program expression number string condition conditionThen
+%type<expr>      program expression number string condition tryCatch logical loop return
+%type<then>      conditionThen
 %type<function>  functionDefine defineArgumentsWithVariables
 %type<call>      call binaryOperator unaryOperator takeMember
-%type<ident>     identifier
+%type<ident>     identifier memberName
 %type<expList>   callArguments expressionList
 %type<identList> defineArguments
 %type<object>    object objectList
 
-%token<token> NUMBER STRING IDENTIFIER NEWLINE DEFINE_OPERATOR CALCULATE_DEFINE_OPERATOR COMPARE_OPERATOR IF ELSE FUNCTION_SEP ELLIPSIS
+%token<expr>  STRING
+%token<token> NUMBER IDENTIFIER NEWLINE DEFINE_OPERATOR CALCULATE_DEFINE_OPERATOR COMPARE_OPERATOR SHIFT_OPERATOR AND_OPERATOR OR_OPERATOR NOT XOR DIV IF ELSE WHILE FOR IN BREAK CONTINUE RETURN TRY CATCH FUNCTION_SEP ELLIPSIS COMMENT
 
 %right ';'
-%right DEFINE_OPERATOR
-%right COMPARE_OPERATOR
+%right DEFINE_OPERATOR CALCULATE_DEFINE_OPERATOR
+%left  OR_OPERATOR
+%left  AND_OPERATOR
+%right NOT
+%left  COMPARE_OPERATOR
+%left  '|'
+%left  XOR
+%left  '&'
+%left  SHIFT_OPERATOR
 
 %left  '+' '-'
-%left  '*' '/' '%'
+%left  '*' '/' '%' DIV
 %left  '^'
 
 %right '!'
 
 %right '.'
+%left  '(' '['
 
 %%
 
@@ -81,16 +88,29 @@ expression
 	| functionDefine
 	{ $$ = $1 }
 	| condition
+	| tryCatch
+	| logical
+	| loop
+	| return
 	| '(' expression ')'
-	{ $$ = $2 }
+	{ $$ = withSpan($2, $<token>1.Pos, $<token>3.End) }
 	| object
 	{ $$ = $1 }
 
 object
 	: '[' objectList ']'
-	{ $$ = $2 }
+	{
+		$$ = $2
+		$$.pos = $<token>1.Pos
+		$$.end = $<token>3.End
+	}
 	| '[' NEWLINE objectList ']'
-	{ $$ = $3 }
+	{
+		$$ = $3
+		$$.multiline = true
+		$$.pos = $<token>1.Pos
+		$$.end = $<token>4.End
+	}
 
 objectList
 	:
@@ -100,32 +120,32 @@ objectList
 	| expression
 	{
 		$$ = NewObject()
-		$$.Indexed = append($$.Indexed, $1)
+		$$.addIndexed($1)
 	}
 	| objectList ',' expression
 	{
 		$$ = $1
-		$$.Indexed = append($1.Indexed, $3)
+		$$.addIndexed($3)
 	}
 	| objectList ',' NEWLINE expression
 	{
 		$$ = $1
-		$$.Indexed = append($1.Indexed, $4)
+		$$.addIndexed($4)
 	}
 	| identifier ':' expression
 	{
 		$$ = NewObject()
-		$$.Named[$1.Key] = $3
+		$$.addNamed($1, $3)
 	}
 	| objectList ',' identifier ':' expression
 	{
 		$$ = $1
-		$$.Named[$3.Key] = $5
+		$$.addNamed($3, $5)
 	}
 	| objectList ',' NEWLINE identifier ':' expression
 	{
 		$$ = $1
-		$$.Named[$4.Key] = $6
+		$$.addNamed($4, $6)
 	}
 	| objectList ','
 	| objectList ',' NEWLINE
@@ -133,15 +153,18 @@ objectList
 number
 	: NUMBER
 	{
-		num, _ := strconv.ParseInt($1.Literal, 10, 64)
-		$$ = Number(num)
+		num, _ := parseNumber($1.Literal)
+		$$ = Literal{
+			Value: num,
+			Pos: $1.Pos,
+			End: $1.End,
+			raw: $1.Literal,
+		}
 	}
 
 string
 	: STRING
-	{
-		$$ = String($1.Literal)
-	}
+	{ $$ = $1 }
 
 identifier
 	: IDENTIFIER
@@ -152,6 +175,16 @@ identifier
 		}
 	}
 
+memberName
+	: identifier
+	| FOR
+	{
+		$$ = Identifier{
+			Key: $1.Literal,
+			Pos: $1.Pos,
+		}
+	}
+
 call
 	: binaryOperator
 	| unaryOperator
@@ -160,7 +193,8 @@ call
 		$$ = FunctionCall {
 			Function: $1,
 			Arguments: $3,
-			Pos: yylex.(*Lexer).lastPosition,
+			Pos: startOf($1),
+			End: $<token>4.End,
 		}
 	}
 	| takeMember '(' callArguments ')'
@@ -168,7 +202,9 @@ call
 		$$ = FunctionCall {
 			Function: $1,
 			Arguments: append([]Expression{$1.Arguments[0]}, $3...),
-			Pos: yylex.(*Lexer).lastPosition,
+			Pos: $1.Pos,
+			End: $<token>4.End,
+			method: true,
 		}
 	}
 
@@ -197,7 +233,8 @@ unaryOperator
 		$$ = FunctionCall {
 			Function: NewIdentifier("-:"),
 			Arguments: []Expression{$2},
-			Pos: yylex.(*Lexer).lastPosition,
+			Pos: $<token>1.Pos,
+			End: endOf($2),
 		}
 	}
 	| '!' expression
@@ -205,7 +242,8 @@ unaryOperator
 		$$ = FunctionCall {
 			Function: NewIdentifier("!:"),
 			Arguments: []Expression{$2},
-			Pos: yylex.(*Lexer).lastPosition,
+			Pos: $<token>1.Pos,
+			End: endOf($2),
 		}
 	}
 
@@ -215,7 +253,8 @@ binaryOperator
 		$$ = FunctionCall {
 			Function: NewIdentifier(":+:"),
 			Arguments: []Expression{$1, $3},
-			Pos: yylex.(*Lexer).lastPosition,
+			Pos: startOf($1),
+			End: endOf($3),
 		}
 	}
 	| expression '-' expression
@@ -223,7 +262,8 @@ binaryOperator
 		$$ = FunctionCall {
 			Function: NewIdentifier(":-:"),
 			Arguments: []Expression{$1, $3},
-			Pos: yylex.(*Lexer).lastPosition,
+			Pos: startOf($1),
+			End: endOf($3),
 		}
 	}
 	| expression '*' expression
@@ -231,7 +271,8 @@ binaryOperator
 		$$ = FunctionCall {
 			Function: NewIdentifier(":*:"),
 			Arguments: []Expression{$1, $3},
-			Pos: yylex.(*Lexer).lastPosition,
+			Pos: startOf($1),
+			End: endOf($3),
 		}
 	}
 	| expression '/' expression
@@ -239,7 +280,17 @@ binaryOperator
 		$$ = FunctionCall {
 			Function: NewIdentifier(":/:"),
 			Arguments: []Expression{$1, $3},
-			Pos: yylex.(*Lexer).lastPosition,
+			Pos: startOf($1),
+			End: endOf($3),
+		}
+	}
+	| expression DIV expression
+	{
+		$$ = FunctionCall {
+			Function: NewIdentifier(":div:"),
+			Arguments: []Expression{$1, $3},
+			Pos: startOf($1),
+			End: endOf($3),
 		}
 	}
 	| expression '%' expression
@@ -247,7 +298,8 @@ binaryOperator
 		$$ = FunctionCall {
 			Function: NewIdentifier(":%:"),
 			Arguments: []Expression{$1, $3},
-			Pos: yylex.(*Lexer).lastPosition,
+			Pos: startOf($1),
+			End: endOf($3),
 		}
 	}
 	| expression '^' expression
@@ -255,7 +307,44 @@ binaryOperator
 		$$ = FunctionCall {
 			Function: NewIdentifier(":^:"),
 			Arguments: []Expression{$1, $3},
-			Pos: yylex.(*Lexer).lastPosition,
+			Pos: startOf($1),
+			End: endOf($3),
+		}
+	}
+	| expression '&' expression
+	{
+		$$ = FunctionCall {
+			Function: NewIdentifier(":&:"),
+			Arguments: []Expression{$1, $3},
+			Pos: startOf($1),
+			End: endOf($3),
+		}
+	}
+	| expression '|' expression
+	{
+		$$ = FunctionCall {
+			Function: NewIdentifier(":|:"),
+			Arguments: []Expression{$1, $3},
+			Pos: startOf($1),
+			End: endOf($3),
+		}
+	}
+	| expression XOR expression
+	{
+		$$ = FunctionCall {
+			Function: NewIdentifier(":xor:"),
+			Arguments: []Expression{$1, $3},
+			Pos: startOf($1),
+			End: endOf($3),
+		}
+	}
+	| expression SHIFT_OPERATOR expression
+	{
+		$$ = FunctionCall {
+			Function: NewIdentifier(":" + $2.Literal + ":"),
+			Arguments: []Expression{$1, $3},
+			Pos: startOf($1),
+			End: endOf($3),
 		}
 	}
 	| expression COMPARE_OPERATOR expression
@@ -263,24 +352,34 @@ binaryOperator
 		$$ = FunctionCall {
 			Function: NewIdentifier(":" + $2.Literal + ":"),
 			Arguments: []Expression{$1, $3},
-			Pos: yylex.(*Lexer).lastPosition,
+			Pos: startOf($1),
+			End: endOf($3),
 		}
 	}
 	| identifier DEFINE_OPERATOR expression
 	{
+		value := $3
+		if fd, ok := value.(FunctionDefine); ok && $2.Literal == ":=" {
+			fd.Doc = yylex.(*Lexer).docComment($1.Pos)
+			fd.name = $1.Key
+			value = fd
+		}
+
 		$$ = FunctionCall {
 			Function: NewIdentifier(":" + $2.Literal + ":"),
-			Arguments: []Expression{$1, $3},
+			Arguments: []Expression{$1, value},
 			Pos: $1.Position(),
+			End: endOf($3),
 		}
 	}
 	| takeMember DEFINE_OPERATOR expression
 	{
 		funcName := $1.Function.(Identifier).Key
 		$$ = FunctionCall {
-			Function: NewIdentifier(funcName[:len(funcName)-1] + "=:"),
+			Function: NewIdentifier(funcName[:len(funcName)-1] + $2.Literal + ":"),
 			Arguments: append($1.Arguments, $3),
 			Pos: $1.Position(),
+			End: endOf($3),
 		}
 	}
 	| takeMember CALCULATE_DEFINE_OPERATOR expression
@@ -294,20 +393,24 @@ binaryOperator
 					Function: NewIdentifier(":" + $2.Literal + ":"),
 					Arguments: []Expression{$1, $3},
 					Pos: $1.Position(),
+					End: endOf($3),
 				},
 			),
 			Pos: $1.Position(),
+			End: endOf($3),
+			compound: $2.Literal,
 		}
 	}
 	| takeMember
 
 takeMember
-	: expression '.' identifier
+	: expression '.' memberName
 	{
 		$$ = FunctionCall {
 			Function: NewIdentifier(":.:"),
 			Arguments: []Expression{$1, $3},
-			Pos: yylex.(*Lexer).lastPosition,
+			Pos: startOf($1),
+			End: endOf($3),
 		}
 	}
 	| expression '[' expression ']'
@@ -315,7 +418,39 @@ takeMember
 		$$ = FunctionCall {
 			Function: NewIdentifier(":[]:"),
 			Arguments: []Expression{$1, $3},
-			Pos: yylex.(*Lexer).lastPosition,
+			Pos: startOf($1),
+			End: $<token>4.End,
+		}
+	}
+
+logical
+	: expression AND_OPERATOR expression
+	{
+		$$ = Logical{
+			Operator: $2.Literal,
+			Left: $1,
+			Right: $3,
+			Pos: startOf($1),
+			End: endOf($3),
+		}
+	}
+	| expression OR_OPERATOR expression
+	{
+		$$ = Logical{
+			Operator: $2.Literal,
+			Left: $1,
+			Right: $3,
+			Pos: startOf($1),
+			End: endOf($3),
+		}
+	}
+	| NOT expression
+	{
+		$$ = Logical{
+			Operator: $1.Literal,
+			Right: $2,
+			Pos: $1.Pos,
+			End: endOf($2),
 		}
 	}
 
@@ -324,13 +459,19 @@ functionDefine
 	{
 		$$ = $2
 		$$.Expression = $4
+		$$.Pos = $<token>1.Pos
+		$$.End = $<token>5.End
+		$$.open = $3.Pos
 	}
 	| '{' expressionList '}'
 	{
 		$$ = FunctionDefine {
 			Arguments: []Identifier{},
 			Expression: $2,
-			Pos: yylex.(*Lexer).lastPosition,
+			Pos: $<token>1.Pos,
+			End: $<token>3.End,
+			open: $<token>1.Pos,
+			block: true,
 		}
 	}
 
@@ -407,25 +548,140 @@ condition
 	{
 		$$ = Condition{
 			Condition: $2,
-			Then: $3,
-			Pos: yylex.(*Lexer).lastPosition,
+			Then: $3.body,
+			Pos: $1.Pos,
+			End: $3.end,
 		}
 	}
 	| IF expression conditionThen ELSE conditionThen
 	{
 		$$ = Condition{
 			Condition: $2,
-			Then: $3,
-			Else: $5,
-			Pos: yylex.(*Lexer).lastPosition,
+			Then: $3.body,
+			Else: $5.body,
+			Pos: $1.Pos,
+			End: $5.end,
+			thenEnd: $3.end,
+			elsePos: $5.start,
 		}
 	}
 
 conditionThen
 	: condition
+	{
+		$$ = conditionBlock{
+			body: $1,
+			start: startOf($1),
+			end: endOf($1),
+		}
+	}
 	| '{' expressionList '}'
 	{
-		$$ = $2
+		$$ = conditionBlock{
+			body: $2,
+			start: $<token>1.Pos,
+			end: $<token>3.End,
+		}
+	}
+
+loop
+	: WHILE expression '{' expressionList '}'
+	{
+		$$ = While{
+			Condition: $2,
+			Body: $4,
+			Pos: $1.Pos,
+			End: $<token>5.End,
+		}
+	}
+	| FOR identifier IN expression '{' expressionList '}'
+	{
+		$$ = For{
+			Variable: $2,
+			Iterable: $4,
+			Body: $6,
+			Pos: $1.Pos,
+			End: $<token>7.End,
+		}
+	}
+	| BREAK
+	{
+		$$ = Break{
+			Pos: $1.Pos,
+			End: $1.End,
+		}
+	}
+	| BREAK '(' ')'
+	{
+		$$ = Break{
+			Pos: $1.Pos,
+			End: $<token>3.End,
+		}
+	}
+	| BREAK '(' expression ')'
+	{
+		$$ = Break{
+			Value: $3,
+			Pos: $1.Pos,
+			End: $<token>4.End,
+		}
+	}
+	| CONTINUE
+	{
+		$$ = Continue{
+			Pos: $1.Pos,
+			End: $1.End,
+		}
+	}
+
+return
+	: RETURN
+	{
+		$$ = Return{
+			Pos: $1.Pos,
+			End: $1.End,
+		}
+	}
+	| RETURN '(' ')'
+	{
+		$$ = Return{
+			Pos: $1.Pos,
+			End: $<token>3.End,
+		}
+	}
+	| RETURN '(' expression ')'
+	{
+		$$ = Return{
+			Value: $3,
+			Pos: $1.Pos,
+			End: $<token>4.End,
+		}
+	}
+
+tryCatch
+	: TRY '{' expressionList '}' CATCH '(' identifier ')' '{' expressionList '}'
+	{
+		$$ = Try{
+			Body: $3,
+			Variable: $7,
+			Catch: $10,
+			Pos: $1.Pos,
+			End: $<token>11.End,
+			bodyEnd: $<token>4.End,
+			catchPos: $5.Pos,
+		}
+	}
+	| TRY '{' expressionList '}' CATCH '(' identifier FUNCTION_SEP expressionList '}'
+	{
+		$$ = Try{
+			Body: $3,
+			Variable: $7,
+			Catch: $9,
+			Pos: $1.Pos,
+			End: $<token>10.End,
+			bodyEnd: $<token>4.End,
+			catchPos: $5.Pos,
+		}
 	}
 
 %%
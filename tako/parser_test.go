@@ -0,0 +1,78 @@
+package tako
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestOperatorPrecedence(t *testing.T) {
+	tests := []struct {
+		src  string
+		tree string
+	}{
+		{"1 | 2 & 3", ":|:(1, :&:(2, 3))"},
+		{"1 & 2 | 3", ":|:(:&:(1, 2), 3)"},
+		{"1 | 2 xor 3", ":|:(1, :xor:(2, 3))"},
+		{"1 xor 2 & 3", ":xor:(1, :&:(2, 3))"},
+		{"1 & 2 << 3", ":&:(1, :<<:(2, 3))"},
+		{"1 << 2 + 1", ":<<:(1, :+:(2, 1))"},
+		{"1 >> 2 << 3", ":<<:(:>>:(1, 2), 3)"},
+		{"1 + 2 * 3", ":+:(1, :*:(2, 3))"},
+		{"7 div 2 * 2", ":*:(:div:(7, 2), 2)"},
+		{"7 % 2 div 3", ":div:(:%:(7, 2), 3)"},
+		{"2 * 3 ^ 2", ":*:(2, :^:(3, 2))"},
+		{"-2 ^ 2", "-:(:^:(2, 2))"},
+		{"1 | 2 == 3", ":==:(:|:(1, 2), 3)"},
+		{"1 < 2 == true", ":==:(:<:(1, 2), true)"},
+		{"1 == 1 != false", ":!=:(:==:(1, 1), false)"},
+		{"a == b && c", "&&(:==:(a, b), c)"},
+		{"a && b || c && d", "||(&&(a, b), &&(c, d))"},
+		{"not a == b && c", "&&(not(:==:(a, b)), c)"},
+		{"a.b(1)[2]", ":[]:(:.:(a, b)(a, 1), 2)"},
+		{"x := 1 + 2", "::=:(x, :+:(1, 2))"},
+		{"x.y += 1 * 2", ":.=:(x, y, :+:(:.:(x, y), :*:(1, 2)))"},
+	}
+
+	for _, tt := range tests {
+		errs, expr := Parse(strings.NewReader(tt.src), "test")
+		if len(errs) > 0 {
+			t.Errorf("%s: %s", tt.src, errs[0])
+			continue
+		}
+
+		if tree := fmt.Sprint(expr); tree != tt.tree {
+			t.Errorf("%s: expected %s but got %s", tt.src, tt.tree, tree)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src    string
+		errors []string
+	}{
+		{"x := (1 +", []string{"test:1:9: syntax error near \"+\":\nx := (1 +\n        ^"}},
+		{"x := 1\ny := )", []string{"test:2:6: syntax error near \")\":\ny := )\n     ^"}},
+		{"x := 1 $ 2", []string{"test:1:8: syntax error near \"$\":\nx := 1 $ 2\n       ^"}},
+		{"break\nf := (){ continue }", []string{
+			"test:1:1: syntax error near \"break\":\nbreak\n^^^^^",
+			"test:2:10: syntax error near \"continue\":\nf := (){ continue }\n         ^^^^^^^^",
+		}},
+	}
+
+	for _, tt := range tests {
+		errs, expr := Parse(strings.NewReader(tt.src), "test")
+		if expr != nil {
+			t.Errorf("%q: expected no expression but got %s", tt.src, expr)
+		}
+
+		var messages []string
+		for _, e := range errs {
+			messages = append(messages, e.Error())
+		}
+		if fmt.Sprintf("%q", messages) != fmt.Sprintf("%q", tt.errors) {
+			t.Errorf("%q: expected errors %q but got %q", tt.src, tt.errors, messages)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package tako
+
+import "fmt"
+
+type Return struct {
+	Value Expression
+	Pos   Position
+	End   Position
+}
+
+func (r Return) String() string {
+	if r.Value == nil {
+		return "return()"
+	}
+	return fmt.Sprintf("return(%s)", r.Value)
+}
+
+func (r Return) Compute(ctx Context) (Expression, error) {
+	if r.Value == nil {
+		return nil, returnSignal{value: Null{}}
+	}
+
+	value, err := ctx.ComputeRecursive(r.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, returnSignal{value: value}
+}
+
+func (r Return) Computable(ctx Context) bool {
+	return true
+}
+
+func (r Return) Position() Position {
+	return r.Pos
+}
+
+type returnSignal struct {
+	value Expression
+}
+
+func (s returnSignal) Error() string {
+	return "return is not in function"
+}
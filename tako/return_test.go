@@ -0,0 +1,19 @@
+package tako
+
+import (
+	"testing"
+)
+
+func TestReturn(t *testing.T) {
+	testEval(t, []evalTest{
+		{src: `f := (n){ if n > 0 { return("pos") }; "other" }; [f(1), f(0)]`, output: "['pos', 'other']"},
+		{src: `f := (){ for x in [1, 2, 3] { if x == 2 { return(x) } }; 0 }; f()`, output: "2"},
+		{src: `f := (){ [1, 2].for((x){ return(x) }); 3 }; f()`, output: "3"},
+		{src: `f := (){ return }; f()`, output: "null"},
+	})
+
+	testEvalError(t, []evalTest{
+		{src: `return(1)`, output: `SyntaxError: syntax error near "return"`},
+		{src: `for x in [1] { return(1) }`, output: `SyntaxError: syntax error near "return"`},
+	})
+}
@@ -0,0 +1,235 @@
+package tako
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const maxStringLength = 1 << 30
+
+var (
+	stringMethods = map[string]Expression{
+		"len": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+			s, err := stringArguments(ctx, "len", args, "self")
+			if err != nil {
+				return nil, err
+			}
+
+			return Integer(utf8.RuneCountInString(s[0])), nil
+		}, "", "self"),
+
+		"sub": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+			s, err := stringArguments(ctx, "sub", args, "self")
+			if err != nil {
+				return nil, err
+			}
+			runes := []rune(s[0])
+
+			start, err := indexArgument(ctx, "start of sub", args["start"], 0, len(runes))
+			if err != nil {
+				return nil, err
+			}
+
+			end, err := indexArgument(ctx, "end of sub", args["end"], start, len(runes))
+			if err != nil {
+				return nil, err
+			}
+
+			return String(runes[start:end]), nil
+		}, "", "self", "start", "end"),
+
+		"split": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+			s, err := stringArguments(ctx, "split", args, "self", "separator")
+			if err != nil {
+				return nil, err
+			}
+
+			result := NewObject()
+			for _, x := range strings.Split(s[0], s[1]) {
+				result.Indexed = append(result.Indexed, String(x))
+			}
+
+			return result, nil
+		}, "", "self", "separator"),
+
+		"join": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+			s, err := stringArguments(ctx, "join", args, "self")
+			if err != nil {
+				return nil, err
+			}
+
+			list, err := ctx.ComputeRecursive(args["list"])
+			if err != nil {
+				return nil, err
+			}
+
+			obj, err := objectArgument(ctx, "list of join", list)
+			if err != nil {
+				return nil, err
+			}
+
+			parts := make([]string, len(obj.Indexed))
+			for i, x := range obj.Indexed {
+				part, ok := x.(String)
+				if !ok {
+					return nil, typeError(ctx, "element of join", x, "string")
+				}
+				parts[i] = string(part)
+			}
+
+			return String(strings.Join(parts, s[0])), nil
+		}, "", "self", "list"),
+
+		"trim": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+			s, err := stringArguments(ctx, "trim", args, "self")
+			if err != nil {
+				return nil, err
+			}
+
+			return String(strings.TrimSpace(s[0])), nil
+		}, "", "self"),
+
+		"upper": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+			s, err := stringArguments(ctx, "upper", args, "self")
+			if err != nil {
+				return nil, err
+			}
+
+			return String(strings.ToUpper(s[0])), nil
+		}, "", "self"),
+
+		"lower": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+			s, err := stringArguments(ctx, "lower", args, "self")
+			if err != nil {
+				return nil, err
+			}
+
+			return String(strings.ToLower(s[0])), nil
+		}, "", "self"),
+
+		"find": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+			s, err := stringArguments(ctx, "find", args, "self", "substring")
+			if err != nil {
+				return nil, err
+			}
+
+			i := strings.Index(s[0], s[1])
+			if i < 0 {
+				return Integer(-1), nil
+			}
+
+			return Integer(utf8.RuneCountInString(s[0][:i])), nil
+		}, "", "self", "substring"),
+
+		"replace": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+			s, err := stringArguments(ctx, "replace", args, "self", "old", "new")
+			if err != nil {
+				return nil, err
+			}
+
+			return String(strings.Replace(s[0], s[1], s[2], -1)), nil
+		}, "", "self", "old", "new"),
+
+		"starts_with": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+			s, err := stringArguments(ctx, "starts_with", args, "self", "prefix")
+			if err != nil {
+				return nil, err
+			}
+
+			return Boolean(strings.HasPrefix(s[0], s[1])), nil
+		}, "", "self", "prefix"),
+
+		"ends_with": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+			s, err := stringArguments(ctx, "ends_with", args, "self", "suffix")
+			if err != nil {
+				return nil, err
+			}
+
+			return Boolean(strings.HasSuffix(s[0], s[1])), nil
+		}, "", "self", "suffix"),
+
+		"repeat": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+			s, err := stringArguments(ctx, "repeat", args, "self")
+			if err != nil {
+				return nil, err
+			}
+
+			count, err := ctx.ComputeRecursive(args["count"])
+			if err != nil {
+				return nil, err
+			}
+
+			n, ok := count.(Integer)
+			if !ok {
+				return nil, typeError(ctx, "count of repeat", count, "integer")
+			}
+			if n < 0 {
+				return nil, locate(ctx, ArithmeticError{reason: "negative repeat count"})
+			}
+			if len(s[0]) > 0 && int64(n) > maxStringLength/int64(len(s[0])) {
+				return nil, locate(ctx, ArithmeticError{reason: "repeat count too large"})
+			}
+
+			return String(strings.Repeat(s[0], int(n))), nil
+		}, "", "self", "count"),
+
+		"chars": NewBuiltInFunction(func(ctx Context, variables *Object, args map[string]Expression) (Expression, error) {
+			s, err := stringArguments(ctx, "chars", args, "self")
+			if err != nil {
+				return nil, err
+			}
+
+			result := NewObject()
+			for _, c := range s[0] {
+				result.Indexed = append(result.Indexed, String(c))
+			}
+
+			return result, nil
+		}, "", "self"),
+	}
+)
+
+func stringArgument(ctx Context, name string, value Expression) (String, error) {
+	s, ok := value.(String)
+	if !ok {
+		return "", typeError(ctx, name, value, "string")
+	}
+	return s, nil
+}
+
+func stringArguments(ctx Context, method string, args map[string]Expression, names ...string) ([]string, error) {
+	result := make([]string, len(names))
+
+	for i, name := range names {
+		value, err := ctx.ComputeRecursive(args[name])
+		if err != nil {
+			return nil, err
+		}
+
+		s, err := stringArgument(ctx, name+" of "+method, value)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = string(s)
+	}
+
+	return result, nil
+}
+
+func indexArgument(ctx Context, name string, arg Expression, min, max int) (int, error) {
+	value, err := ctx.ComputeRecursive(arg)
+	if err != nil {
+		return 0, err
+	}
+
+	if !isNumber(value, true) {
+		return 0, typeError(ctx, name, value, "integer")
+	}
+
+	i, _ := toIndex(value)
+	if i < min || max < i {
+		return 0, OutOfBoundsError{min: min, max: max, got: value, pos: ctx.callPosition(), end: ctx.callEnd()}
+	}
+
+	return i, nil
+}
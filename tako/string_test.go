@@ -0,0 +1,44 @@
+package tako
+
+import (
+	"testing"
+)
+
+func TestStringMethods(t *testing.T) {
+	testEval(t, []evalTest{
+		{src: `"héllo".len()`, output: "5"},
+		{src: `"héllo".sub(1, 3)`, output: "él"},
+		{src: `"abc".sub(1, 1)`, output: ""},
+		{src: `"ab".repeat(3)`, output: "ababab"},
+		{src: `"".repeat(1 << 40)`, output: ""},
+		{src: `"a,b".split(",")`, output: "['a', 'b']"},
+		{src: `"abc".find("c")`, output: "2"},
+	})
+}
+
+func TestStringMethodErrors(t *testing.T) {
+	testEvalError(t, []evalTest{
+		{src: `"abc".sub(2, 1)`, output: "OutOfBoundsError: index 1 is out of bounds (must be between 2 and 3)"},
+		{src: `"abc".sub(4, 4)`, output: "OutOfBoundsError: index 4 is out of bounds (must be between 0 and 3)"},
+		{src: `"abc".repeat(-1)`, output: "ArithmeticError: negative repeat count"},
+		{src: `"abc".repeat(1 << 62)`, output: "ArithmeticError: repeat count too large"},
+		{src: `"ab".repeat(1 << 29 + 1)`, output: "ArithmeticError: repeat count too large"},
+	})
+}
+
+func TestStringLiterals(t *testing.T) {
+	testEval(t, []evalTest{
+		{src: "`C:\\tako\\n`", output: `C:\tako\n`},
+		{src: `"\u{1F419} \x48\x49"`, output: "\U0001F419 HI"},
+		{src: `"tab\tnew\\n"`, output: "tab\tnew\\n"},
+		{src: `'''it's "quoted"'''`, output: `it's "quoted"`},
+		{src: "\"\"\"\n\t\tdear,\n\t\t  indented\n\n\t\tbye\n\t\t\"\"\"", output: "dear,\n  indented\n\nbye\n"},
+		{src: "x := \"\"\"\n\tline\n\t\"\"\"\ntry { y } catch (e) { e.position }", output: "eval:4:7"},
+		{src: "x := `a\nb`; try { y } catch (e) { e.position }", output: "eval:2:11"},
+	})
+
+	testEvalError(t, []evalTest{
+		{src: `"\u{110000}"`, output: `SyntaxError: syntax error near "\\u{110000}"`},
+		{src: `"\x4"`, output: `SyntaxError: syntax error near "\\x4"`},
+	})
+}
@@ -0,0 +1,93 @@
+package tako
+
+import (
+	"fmt"
+	"strings"
+)
+
+type StackFrame struct {
+	Name string
+	Pos  Position
+	End  Position
+
+	parent *StackFrame
+}
+
+func (sf *StackFrame) List() []StackFrame {
+	var frames []StackFrame
+	for cur := sf; cur != nil; cur = cur.parent {
+		frames = append(frames, *cur)
+	}
+
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+
+	return frames
+}
+
+type TracedError struct {
+	Err   error
+	Stack []StackFrame
+}
+
+func NewTracedError(err error, frame *StackFrame) error {
+	switch err.(type) {
+	case TracedError, loopSignal, returnSignal:
+		return err
+	}
+
+	return TracedError{
+		Err:   err,
+		Stack: frame.List(),
+	}
+}
+
+func (e TracedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e TracedError) Unwrap() error {
+	return e.Err
+}
+
+func (e TracedError) Traceback() string {
+	type entry struct {
+		pos  Position
+		name string
+	}
+
+	var entries []entry
+
+	name := "<main>"
+	for _, f := range e.Stack {
+		entries = append(entries, entry{pos: f.Pos, name: name})
+
+		name = f.Name
+		if name == "" {
+			name = "<anonymous>"
+		}
+	}
+	if le, ok := e.Err.(LocatedError); ok {
+		entries = append(entries, entry{pos: le.Position(), name: name})
+	}
+
+	lines := []string{"Traceback (most recent call last):"}
+	for i := 0; i < len(entries); {
+		lines = append(lines, fmt.Sprintf("  %s, in %s", entries[i].pos, entries[i].name))
+
+		repeated := 0
+		for i+repeated+1 < len(entries) && entries[i+repeated+1] == entries[i] {
+			repeated++
+		}
+		if repeated > 0 {
+			lines = append(lines, fmt.Sprintf("  [previous line repeated %d more times]", repeated))
+		}
+
+		i += repeated + 1
+	}
+
+	lines = append(lines, e.Err.Error())
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,59 @@
+package tako
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTraceback(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       string
+		frames    []string
+		traceback string
+	}{
+		{
+			"nested calls",
+			"f := (){ g() + 1 }\ng := (){ x }\nf()",
+			[]string{"f eval:3:1", "g eval:1:10"},
+			"Traceback (most recent call last):\n  eval:3:1, in <main>\n  eval:1:10, in f\n  eval:2:10, in g\neval:2:10: x is not defined:\ng := (){ x }\n         ^",
+		},
+		{
+			"recursion",
+			"f := (n){ if n == 0 { x } else { f(n - 1) + 1 } }\nf(3)",
+			[]string{"f eval:2:1", "f eval:1:34", "f eval:1:34", "f eval:1:34"},
+			"Traceback (most recent call last):\n  eval:2:1, in <main>\n  eval:1:34, in f\n  [previous line repeated 2 more times]\n  eval:1:23, in f\neval:1:23: x is not defined:\nf := (n){ if n == 0 { x } else { f(n - 1) + 1 } }\n                      ^",
+		},
+		{
+			"anonymous function",
+			"((){ x })()",
+			[]string{" eval:1:1"},
+			"Traceback (most recent call last):\n  eval:1:1, in <main>\n  eval:1:6, in <anonymous>\neval:1:6: x is not defined:\n((){ x })()\n     ^",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, e := range engines {
+			_, err := e.interpreter().Eval(tt.src)
+
+			var te TracedError
+			if !errors.As(err, &te) {
+				t.Errorf("%s (%s): expected traced error but got %v", tt.name, e.name, err)
+				continue
+			}
+
+			var frames []string
+			for _, f := range te.Stack {
+				frames = append(frames, f.Name+" "+f.Pos.String())
+			}
+			if strings.Join(frames, ", ") != strings.Join(tt.frames, ", ") {
+				t.Errorf("%s (%s): expected frames %q but got %q", tt.name, e.name, tt.frames, frames)
+			}
+
+			if traceback := te.Traceback(); traceback != tt.traceback {
+				t.Errorf("%s (%s): expected traceback\n%s\nbut got\n%s", tt.name, e.name, tt.traceback, traceback)
+			}
+		}
+	}
+}
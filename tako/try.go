@@ -0,0 +1,45 @@
+package tako
+
+import "fmt"
+
+type Try struct {
+	Body     Expression
+	Variable Identifier
+	Catch    Expression
+	Pos      Position
+	End      Position
+
+	bodyEnd  Position
+	catchPos Position
+}
+
+func (t Try) String() string {
+	return fmt.Sprintf("try(%s, (%s){%s})", t.Body, t.Variable, t.Catch)
+}
+
+func (t Try) Compute(ctx Context) (Expression, error) {
+	result, err := ctx.ComputeRecursive(t.Body)
+	if err == nil {
+		return result, nil
+	}
+
+	switch err.(type) {
+	case loopSignal, returnSignal:
+		return nil, err
+	}
+
+	newCtx := ctx.MakeScope()
+	if err := newCtx.Define(t.Variable, ErrorToExpression(err)); err != nil {
+		return nil, err
+	}
+
+	return NewTailCall(newCtx, t.Catch), nil
+}
+
+func (t Try) Computable(ctx Context) bool {
+	return true
+}
+
+func (t Try) Position() Position {
+	return t.Pos
+}
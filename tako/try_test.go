@@ -0,0 +1,21 @@
+package tako
+
+import (
+	"testing"
+)
+
+func TestTryCatch(t *testing.T) {
+	testEval(t, []evalTest{
+		{src: `try { 1 + 2 } catch (e) { e }`, output: "3"},
+		{src: `try { x } catch (e) { [e.kind, e.message, e.position] }`, output: "['NotDefinedError', 'x is not defined', 'eval:1:7']"},
+		{src: `try { [1][3] } catch (e) { e.kind }`, output: "OutOfBoundsError"},
+		{src: `try { throw([kind: "ValueError", message: "bad"]) } catch (e) { [e.kind, e.message] }`, output: "['ValueError', 'bad']"},
+		{src: `f := (){ throw("deep") }; try { f() } catch (e) { e }`, output: "deep"},
+		{src: `try { try { throw(1) } catch (e) { throw(e + 1) } } catch (e) { e }`, output: "2"},
+	})
+
+	testEvalError(t, []evalTest{
+		{src: `throw([kind: "ValueError", message: "bad"])`, output: "ValueError: bad"},
+		{src: `try { 1 } catch (e) { e }; throw([kind: "ValueError", message: "after"])`, output: "ValueError: after"},
+	})
+}
@@ -0,0 +1,136 @@
+package tako
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+type Integer int64
+
+func (i Integer) String() string {
+	return strconv.FormatInt(int64(i), 10)
+}
+
+func (i Integer) Compute(ctx Context) (Expression, error) {
+	return i, nil
+}
+
+func (i Integer) Computable(ctx Context) bool {
+	return false
+}
+
+type BigInt struct {
+	value *big.Int
+}
+
+func NewBigInt(x *big.Int) Expression {
+	return normalize(new(big.Int).Set(x))
+}
+
+func (b BigInt) Int() *big.Int {
+	return new(big.Int).Set(b.value)
+}
+
+func (b BigInt) String() string {
+	return b.value.String()
+}
+
+func (b BigInt) Compute(ctx Context) (Expression, error) {
+	return b, nil
+}
+
+func (b BigInt) Computable(ctx Context) bool {
+	return false
+}
+
+type Float float64
+
+func (f Float) String() string {
+	x := float64(f)
+	if x == math.Trunc(x) && math.Abs(x) < 1e21 {
+		return strconv.FormatFloat(x, 'f', 1, 64)
+	}
+	return strconv.FormatFloat(x, 'g', -1, 64)
+}
+
+func (f Float) Compute(ctx Context) (Expression, error) {
+	return f, nil
+}
+
+func (f Float) Computable(ctx Context) bool {
+	return false
+}
+
+type String string
+
+func (s String) String() string {
+	x := strings.Replace(string(s), "\\", "\\\\", -1)
+	x = strings.Replace(x, "\n", "\\n", -1)
+	x = strings.Replace(x, "\r", "\\r", -1)
+	x = strings.Replace(x, "\t", "\\t", -1)
+	x = strings.Replace(x, "'", "\\'", -1)
+	return fmt.Sprintf("'%s'", x)
+}
+
+func (s String) Compute(ctx Context) (Expression, error) {
+	return s, nil
+}
+
+func (s String) Computable(ctx Context) bool {
+	return false
+}
+
+type Boolean bool
+
+func (b Boolean) String() string {
+	return fmt.Sprint(bool(b))
+}
+
+func (b Boolean) Compute(ctx Context) (Expression, error) {
+	return b, nil
+}
+
+func (b Boolean) Computable(ctx Context) bool {
+	return false
+}
+
+type Null struct{}
+
+func (n Null) String() string {
+	return "null"
+}
+
+func (n Null) Compute(ctx Context) (Expression, error) {
+	return n, nil
+}
+
+func (n Null) Computable(ctx Context) bool {
+	return false
+}
+
+func typeName(value Expression) string {
+	switch v := value.(type) {
+	case Literal:
+		return typeName(v.Value)
+	case Integer, BigInt:
+		return "integer"
+	case Float:
+		return "float"
+	case Decimal:
+		return "decimal"
+	case String:
+		return "string"
+	case Boolean:
+		return "boolean"
+	case Null:
+		return "null"
+	case *Object:
+		return "object"
+	case Function:
+		return "function"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,610 @@
+package tako
+
+import "strings"
+
+type vmScope struct {
+	slots  []Expression
+	parent *vmScope
+}
+
+func (s *vmScope) lookup(slot variableSlot) *Expression {
+	cur := s
+	for i := 0; i < slot.depth; i++ {
+		cur = cur.parent
+	}
+	return &cur.slots[slot.index]
+}
+
+type frame struct {
+	closure *Closure
+	proto   *Proto
+	scope   *vmScope
+	pc      int
+	base    int
+	site    *callSite
+	name    string
+	trace   *StackFrame
+	loop    bool
+	catch   bool
+}
+
+type iterator struct {
+	object *Object
+	index  int
+}
+
+func (it *iterator) Compute(ctx Context) (Expression, error) {
+	return it, nil
+}
+
+func (it *iterator) Computable(ctx Context) bool {
+	return false
+}
+
+type handler struct {
+	frame  int
+	stack  int
+	proto  *Proto
+	resume int
+}
+
+type VM struct {
+	ctx           Context
+	stack         []Expression
+	fastOperators bool
+}
+
+func NewVM(ctx Context) *VM {
+	vm := &VM{
+		ctx:           ctx,
+		fastOperators: true,
+	}
+
+	for name := range binaryOperators {
+		vm.fastOperators = vm.fastOperators && isBuiltinBinding(ctx, name)
+	}
+	for name := range unaryOperators {
+		vm.fastOperators = vm.fastOperators && isBuiltinBinding(ctx, name)
+	}
+
+	return vm
+}
+
+func isBuiltinBinding(ctx Context, key string) bool {
+	for cur := &ctx; cur != nil; cur = cur.parent {
+		if _, ok := cur.values[key]; ok {
+			return cur.parent == nil
+		}
+	}
+	return false
+}
+
+func (vm *VM) Run(proto *Proto) (Expression, error) {
+	return vm.execute(&frame{proto: proto, base: len(vm.stack), trace: vm.ctx.frame})
+}
+
+func (vm *VM) call(c *Closure, args []Expression) (Expression, error) {
+	fr := &frame{base: len(vm.stack), trace: vm.ctx.calleeFrame()}
+
+	vm.enter(fr, c, args)
+	return vm.execute(fr)
+}
+
+func traceOf(frames []frame, i int) *StackFrame {
+	j := i
+	for j > 0 && frames[j].trace == nil {
+		j--
+	}
+
+	for j++; j <= i; j++ {
+		parent := frames[j-1].trace
+		if frames[j].site == nil {
+			frames[j].trace = parent
+		} else {
+			frames[j].trace = &StackFrame{
+				Name:   frames[j].name,
+				Pos:    frames[j].site.pos,
+				End:    frames[j].site.end,
+				parent: parent,
+			}
+		}
+	}
+
+	return frames[i].trace
+}
+
+func (vm *VM) enter(fr *frame, c *Closure, args []Expression) {
+	slots := make([]Expression, c.proto.slots)
+	argc := copy(slots, args[:len(c.proto.Define.Arguments)])
+
+	if c.proto.Define.VariableArgument != nil {
+		obj := NewObject()
+		obj.Indexed = append(obj.Indexed, args[argc:]...)
+		slots[argc] = obj
+	}
+
+	fr.closure = c
+	fr.proto = c.proto
+	fr.scope = &vmScope{slots: slots, parent: c.scope}
+	fr.pc = 0
+}
+
+func (vm *VM) push(e Expression) {
+	vm.stack = append(vm.stack, e)
+}
+
+func (vm *VM) pop() Expression {
+	e := vm.stack[len(vm.stack)-1]
+	vm.stack = vm.stack[:len(vm.stack)-1]
+	return e
+}
+
+func (vm *VM) execute(first *frame) (Expression, error) {
+	frames := make([]frame, 1, 16)
+	frames[0] = *first
+	fr := &frames[0]
+
+	var handlers []handler
+
+	for {
+		inst := fr.proto.Code[fr.pc]
+		fr.pc++
+
+		var err error
+
+		switch inst.Op {
+		case OpConst:
+			vm.push(fr.proto.constants[inst.A])
+
+		case OpLoad:
+			var v Expression
+			if v, err = vm.load(fr, fr.proto.variables[inst.A]); err == nil {
+				vm.push(v)
+			}
+
+		case OpDefine:
+			ref := fr.proto.variables[inst.A]
+			value := vm.stack[len(vm.stack)-1]
+
+			if fr.scope == nil || len(ref.candidates) == 0 || ref.candidates[0].depth != 0 {
+				err = vm.globals(fr).Define(ref.ident, value)
+			} else if slot := fr.scope.lookup(ref.candidates[0]); *slot != nil {
+				err = AlreadyDefinedError(ref.ident)
+			} else {
+				*slot = value
+			}
+
+		case OpPut:
+			err = vm.put(fr, fr.proto.variables[inst.A], vm.stack[len(vm.stack)-1])
+
+		case OpPop:
+			vm.pop()
+
+		case OpJump:
+			fr.pc = inst.A
+
+		case OpJumpIfFalse:
+			if b, ok := vm.pop().(Boolean); !ok {
+				err = fr.proto.conditions[inst.B].typeError()
+			} else if !b {
+				fr.pc = inst.A
+			}
+
+		case OpClosure:
+			vm.push(&Closure{
+				proto: fr.proto.protos[inst.A],
+				scope: fr.scope,
+				ctx:   vm.globals(fr),
+			})
+
+		case OpObject:
+			shape := fr.proto.objects[inst.A]
+			start := len(vm.stack) - shape.indexed - len(shape.named)
+
+			obj := &Object{
+				Indexed: make([]Expression, shape.indexed),
+				Named:   make(map[string]Expression, len(shape.named)),
+			}
+			copy(obj.Indexed, vm.stack[start:])
+			for i, k := range shape.named {
+				obj.Named[k] = vm.stack[start+shape.indexed+i]
+			}
+
+			vm.stack = vm.stack[:start]
+			vm.push(obj)
+
+		case OpConcat:
+			start := len(vm.stack) - inst.A
+
+			ss := make([]string, inst.A)
+			for i, x := range vm.stack[start:] {
+				ss[i] = display(x)
+			}
+
+			vm.stack = vm.stack[:start]
+			vm.push(String(strings.Join(ss, "")))
+
+		case OpBinary:
+			site := fr.proto.calls[inst.A]
+			y := vm.pop()
+			x := vm.pop()
+
+			r, ok := vm.binary(site.name, x, y)
+			if !ok {
+				r, err = vm.callOperator(frames, site, x, y)
+			}
+			if err == nil {
+				vm.push(r)
+			}
+
+		case OpUnary:
+			site := fr.proto.calls[inst.A]
+			x := vm.pop()
+
+			r, ok := vm.unary(site.name, x)
+			if !ok {
+				r, err = vm.callOperator(frames, site, x)
+			}
+			if err == nil {
+				vm.push(r)
+			}
+
+		case OpCall, OpTailCall:
+			site := fr.proto.calls[inst.A]
+			if receiver := len(vm.stack) - site.argc; site.method && isModule(vm.stack[receiver]) {
+				vm.stack = append(vm.stack[:receiver], vm.stack[receiver+1:]...)
+				site.argc--
+			}
+			fi := len(vm.stack) - site.argc - 1
+			args := vm.stack[fi+1:]
+
+			c, ok := vm.stack[fi].(*Closure)
+			if !ok {
+				ctx := vm.ctx
+				ctx.frame = traceOf(frames, len(frames)-1)
+
+				var r Expression
+				if r, err = vm.callFunction(ctx, site, vm.stack[fi], args); err == nil {
+					vm.stack = vm.stack[:fi]
+					vm.push(r)
+				}
+				break
+			}
+
+			if err = checkArguments(site, c); err != nil {
+				break
+			}
+
+			if inst.Op == OpTailCall {
+				if len(frames) > 1 && fr.site != nil {
+					fr.name = site.name
+					fr.trace = nil
+				} else if t := traceOf(frames, len(frames)-1); t != nil {
+					fr.trace = &StackFrame{
+						Name:   site.name,
+						Pos:    t.Pos,
+						End:    t.End,
+						parent: t.parent,
+					}
+				}
+
+				fr.loop, fr.catch = false, false
+				vm.enter(fr, c, args)
+				vm.stack = vm.stack[:fr.base]
+			} else {
+				frames = append(frames, frame{base: fi, site: &fr.proto.calls[inst.A], name: site.name})
+				fr = &frames[len(frames)-1]
+				vm.enter(fr, c, args)
+				vm.stack = vm.stack[:fi]
+			}
+
+		case OpTry:
+			handlers = append(handlers, handler{
+				frame:  len(frames) - 1,
+				stack:  len(vm.stack),
+				proto:  fr.proto.protos[inst.A],
+				resume: inst.B,
+			})
+
+		case OpEndTry:
+			handlers = handlers[:len(handlers)-1]
+
+		case OpIter:
+			value := vm.pop()
+			if obj, ok := value.(*Object); ok {
+				vm.push(NewObject())
+				vm.push(&iterator{object: obj})
+			} else {
+				err = fr.proto.loops[inst.A].typeError(value)
+			}
+
+		case OpNext:
+			it := vm.stack[len(vm.stack)-1].(*iterator)
+			if it.index < len(it.object.Indexed) {
+				vm.push(it.object.Indexed[it.index])
+				it.index++
+			} else {
+				fr.pc = inst.A
+			}
+
+		case OpLoop:
+			base := len(vm.stack) - inst.B
+			body := &Closure{
+				proto: fr.proto.protos[inst.A],
+				scope: fr.scope,
+				ctx:   vm.globals(fr),
+			}
+
+			frames = append(frames, frame{base: base, loop: true})
+			fr = &frames[len(frames)-1]
+			vm.enter(fr, body, vm.stack[base:])
+			vm.stack = vm.stack[:base]
+
+		case OpCollect:
+			value := vm.pop()
+			result := vm.stack[len(vm.stack)-inst.B].(*Object)
+			result.Indexed = append(result.Indexed, value)
+
+		case OpBreak:
+			var value Expression
+			if inst.A == 2 {
+				value = vm.pop()
+			}
+
+			i := len(frames) - 1
+			for !frames[i].loop {
+				i--
+			}
+			for len(handlers) > 0 && handlers[len(handlers)-1].frame >= i {
+				handlers = handlers[:len(handlers)-1]
+			}
+
+			vm.stack = vm.stack[:frames[i].base]
+			frames = frames[:i]
+			fr = &frames[i-1]
+
+			collect := fr.proto.Code[fr.pc]
+			fr.pc++
+			if inst.A != 0 {
+				if value != nil {
+					result := vm.stack[len(vm.stack)-collect.B].(*Object)
+					result.Indexed = append(result.Indexed, value)
+				}
+				fr.pc = collect.A
+			}
+
+		case OpLeave:
+			result := vm.pop()
+
+			i := len(frames) - 1
+			for frames[i].loop || frames[i].catch {
+				i--
+			}
+			for len(handlers) > 0 && handlers[len(handlers)-1].frame >= i {
+				handlers = handlers[:len(handlers)-1]
+			}
+
+			vm.stack = vm.stack[:frames[i].base]
+
+			frames = frames[:i]
+			if len(frames) == 0 {
+				return result, nil
+			}
+
+			fr = &frames[len(frames)-1]
+			vm.push(result)
+
+		case OpReturn:
+			result := vm.pop()
+			vm.stack = vm.stack[:fr.base]
+
+			frames = frames[:len(frames)-1]
+			if len(frames) == 0 {
+				return result, nil
+			}
+
+			fr = &frames[len(frames)-1]
+			vm.push(result)
+		}
+
+		if err != nil {
+			err = NewTracedError(err, traceOf(frames, len(frames)-1))
+
+			if len(handlers) == 0 {
+				return nil, err
+			}
+
+			h := handlers[len(handlers)-1]
+			handlers = handlers[:len(handlers)-1]
+
+			frames = frames[:h.frame+1]
+			fr = &frames[h.frame]
+			fr.pc = h.resume
+			vm.stack = vm.stack[:h.stack]
+
+			catch := &Closure{
+				proto: h.proto,
+				scope: fr.scope,
+				ctx:   vm.globals(fr),
+			}
+
+			frames = append(frames, frame{base: h.stack, catch: true})
+			fr = &frames[len(frames)-1]
+			vm.enter(fr, catch, []Expression{ErrorToExpression(err)})
+		}
+	}
+}
+
+func (vm *VM) globals(fr *frame) Context {
+	if fr.closure != nil {
+		return fr.closure.ctx
+	}
+	return vm.ctx
+}
+
+func (vm *VM) load(fr *frame, ref variableReference) (Expression, error) {
+	for _, c := range ref.candidates {
+		if v := *fr.scope.lookup(c); v != nil {
+			return v, nil
+		}
+	}
+
+	return vm.globals(fr).Get(ref.ident)
+}
+
+func (vm *VM) put(fr *frame, ref variableReference, value Expression) error {
+	for _, c := range ref.candidates {
+		if slot := fr.scope.lookup(c); *slot != nil {
+			*slot = value
+			return nil
+		}
+	}
+
+	return vm.globals(fr).Put(ref.ident, value)
+}
+
+func checkArguments(site callSite, f Function) error {
+	va := f.GetVariableArgument()
+	argc := len(f.GetArguments())
+
+	if (va == nil && site.argc != argc) || (va != nil && site.argc < argc) {
+		return MissmatchArgumentError{
+			excepted: argc,
+			got:      site.argc,
+			pos:      site.pos,
+			end:      site.end,
+			name:     site.name,
+		}
+	}
+
+	return nil
+}
+
+func (vm *VM) callFunction(ctx Context, site callSite, value Expression, args []Expression) (Expression, error) {
+	f, ok := value.(Function)
+	if !ok {
+		return nil, NotFunctionError{value: site.function, pos: site.pos, end: site.end}
+	}
+
+	if err := checkArguments(site, f); err != nil {
+		return nil, err
+	}
+
+	arguments := f.GetArguments()
+
+	var variables *Object
+	if f.GetVariableArgument() != nil {
+		variables = NewObject()
+		variables.Indexed = append(variables.Indexed, args[len(arguments):]...)
+	}
+
+	if bf, ok := f.(BuiltInFunction); ok {
+		as := make(map[string]Expression, len(bf.names))
+		for i, name := range bf.names {
+			as[name] = args[i]
+		}
+		r, err := bf.Function(ctx.pushFrame(site.name, site.pos, site.end), variables, as)
+		if te, ok := err.(ThrownError); ok && te.pos == (Position{}) {
+			te.pos, te.end = site.pos, site.end
+			return nil, te
+		}
+		return r, err
+	}
+
+	as := make(map[Identifier]Expression, len(arguments))
+	for i, a := range arguments {
+		as[a] = args[i]
+	}
+
+	r, err := f.Call(ctx.pushFrame(site.name, site.pos, site.end), as, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx.ComputeRecursive(r)
+}
+
+func (vm *VM) callOperator(frames []frame, site callSite, args ...Expression) (Expression, error) {
+	f, err := vm.ctx.Get(site.function.(Identifier))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := vm.ctx
+	ctx.frame = traceOf(frames, len(frames)-1)
+
+	return vm.callFunction(ctx, site, f, args)
+}
+
+func (vm *VM) binary(op string, x, y Expression) (Expression, bool) {
+	if !vm.fastOperators {
+		return nil, false
+	}
+
+	switch a := x.(type) {
+	case Integer, BigInt, Float, Decimal:
+		if decimalMix(op, x, y) != nil {
+			return nil, false
+		}
+
+		switch op {
+		case ":==:":
+			return Boolean(equals(x, y)), true
+		case ":!=:":
+			return Boolean(!equals(x, y)), true
+		}
+
+		value, err := calculate(vm.ctx, op, x, y)
+		return value, err == nil
+
+	case String:
+		b, ok := y.(String)
+		if !ok {
+			return nil, false
+		}
+
+		switch op {
+		case ":+:":
+			return a + b, true
+		case ":==:":
+			return Boolean(a == b), true
+		case ":!=:":
+			return Boolean(a != b), true
+		}
+
+	case Boolean:
+		b, ok := y.(Boolean)
+		if !ok {
+			return nil, false
+		}
+
+		switch op {
+		case ":==:":
+			return Boolean(a == b), true
+		case ":!=:":
+			return Boolean(a != b), true
+		}
+	}
+
+	return nil, false
+}
+
+func (vm *VM) unary(op string, x Expression) (Expression, bool) {
+	if !vm.fastOperators {
+		return nil, false
+	}
+
+	switch a := x.(type) {
+	case Integer, BigInt, Float, Decimal:
+		if op == "-:" {
+			return negate(a)
+		}
+
+	case Boolean:
+		if op == "!:" {
+			return !a, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,91 @@
+package tako
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func runExample(t testing.TB, path string, useVM bool) string {
+	var output bytes.Buffer
+
+	in := NewInterpreter()
+	in.UseVM = useVM
+	in.Stdout = &output
+
+	if _, err := in.EvalFile(path); err != nil {
+		t.Fatalf("%s (vm=%v): %s", path, useVM, err)
+	}
+	return output.String()
+}
+
+func TestVMMatchesTreeWalker(t *testing.T) {
+	paths, err := filepath.Glob("../examples/*.tako")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no examples found")
+	}
+
+	for _, path := range paths {
+		expected := runExample(t, path, false)
+		if output := runExample(t, path, true); output != expected {
+			t.Errorf("%s: output of vm is different from tree-walker\n--- tree-walker\n%s\n--- vm\n%s", path, expected, output)
+		}
+	}
+}
+
+func TestVMReportsCompileError(t *testing.T) {
+	in := NewInterpreter()
+	in.UseVM = true
+
+	_, err := in.Eval(`:+: := (a, b){ a }`)
+	if _, ok := err.(CompileError); !ok {
+		t.Errorf("expected CompileError but got %#v", err)
+	}
+}
+
+const benchmarkSource = `
+fib := (n){
+	if n < 2 {
+		n
+	} else {
+		fib(n - 1) + fib(n - 2)
+	}
+}
+
+total := 0
+for i in [1, 2, 3, 4, 5, 6, 7, 8, 9, 10] {
+	j := 0
+	while j < 200 {
+		total = total + i * j % 7
+		j = j + 1
+	}
+}
+
+fib(18) + total
+`
+
+func benchmarkEngine(b *testing.B, useVM bool) {
+	errs, expr := Parse(bytes.NewBufferString(benchmarkSource), "benchmark")
+	if len(errs) > 0 {
+		b.Fatal(errs[0])
+	}
+
+	for i := 0; i < b.N; i++ {
+		in := NewInterpreter()
+		in.UseVM = useVM
+		if _, err := in.Run(expr); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTreeWalker(b *testing.B) {
+	benchmarkEngine(b, false)
+}
+
+func BenchmarkVM(b *testing.B) {
+	benchmarkEngine(b, true)
+}